@@ -0,0 +1,80 @@
+package gut
+
+import (
+	"fmt"
+	r "reflect"
+
+	"github.com/tompston/gut/ir"
+)
+
+// go types which are emitted as branded types
+var brandTypes = make(map[r.Type]bool)
+
+// Brand emits the named Go types of the passed in values (like the ids,
+// type UserID uuid.UUID or type OrgID int64) as branded types, so the ids
+// of different types can not be mixed up in typescript. A function which
+// converts the underlying value into the branded type is emitted as well.
+// The types which are not registered can be branded with Type.Brand, when
+// they are converted on their own.
+//
+// Example
+//
+//	gut.Brand(UserID{}, OrgID(0))
+//
+//	// export type UserID = UuidType & { readonly __brand: "UserID" }
+//	// export function toUserID(value: UuidType): UserID
+func Brand(values ...interface{}) {
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+
+	for _, v := range values {
+		t := r.TypeOf(v)
+		if !canBrand(t) {
+			panic(fmt.Sprintf("Only named types which are not structs can be branded! %v", t))
+		}
+		brandTypes[t] = true
+	}
+}
+
+// canBrand checks if the type is a named type (declared in a package,
+// so not a builtin like string), which is not a struct or an interface
+func canBrand(t r.Type) bool {
+	return t != nil && t.PkgPath() != "" && t.Kind() != r.Struct && t.Kind() != r.Interface
+}
+
+// isBranded checks if the type was registered with Brand
+func isBranded(t r.Type) bool {
+	aliasMu.RLock()
+	defer aliasMu.RUnlock()
+
+	return brandTypes[t]
+}
+
+// brandedName returns the name of the branded type, if the type was
+// registered with Brand (used by the builder to declare the type).
+func brandedName(t r.Type) (string, bool) {
+	if !isBranded(t) {
+		return "", false
+	}
+	return ir.TypeName(t), true
+}
+
+// isBrand checks if the declaration is emitted as a branded type
+func (e *tsEmitter) isBrand(decl *ir.Decl) bool {
+	if decl.Kind != ir.AliasDecl {
+		return false
+	}
+	return e.settings[decl].Brand || decl.Go != nil && isBranded(decl.Go)
+}
+
+// brandedType returns the branded type and the to<Name> function which
+// converts the underlying value into the branded type (the function has
+// its own name, so the type and the value can both be exported).
+func (e *tsEmitter) brandedType(decl *ir.Decl) string {
+	base := e.toTS(decl.Type)
+	if kind := decl.Type.Kind; kind == ir.Func || kind == ir.Union {
+		base = fmt.Sprintf("(%s)", base)
+	}
+	return fmt.Sprintf("export type %s = %s & { readonly __brand: %q }\n\n", decl.Name, base, decl.Name) +
+		fmt.Sprintf("export function to%s(value: %s): %s {\n  return value as %s\n}\n\n", decl.Name, e.toTS(decl.Type), decl.Name, decl.Name)
+}
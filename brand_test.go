@@ -0,0 +1,145 @@
+package gut
+
+import (
+	r "reflect"
+	"strings"
+	"testing"
+
+	. "github.com/tompston/gut/types"
+)
+
+// brand registers the types as branded, until the end of the test
+func brand(t *testing.T, values ...interface{}) {
+	Brand(values...)
+	t.Cleanup(func() {
+		aliasMu.Lock()
+		defer aliasMu.Unlock()
+		brandTypes = make(map[r.Type]bool)
+	})
+}
+
+func TestBrandedFields(t *testing.T) {
+	brand(t, UserID{}, OrgID(0))
+
+	generated := Convert(Membership{})
+	expected := `
+	export interface Membership {
+		user: UserID
+		org: OrgID
+		project?: string
+		owners: UserID[]
+	}
+
+	export type UserID = UuidType & { readonly __brand: "UserID" }
+
+	export function toUserID(value: UuidType): UserID {
+		return value as UserID
+	}
+
+	export type OrgID = BigIntType & { readonly __brand: "OrgID" }
+
+	export function toOrgID(value: BigIntType): OrgID {
+		return value as OrgID
+	}`
+
+	if stripSpaces(generated) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+}
+
+func TestBrandedType(t *testing.T) {
+	tests := []struct {
+		generated string
+		expected  string
+	}{
+		{
+			generated: Convert(ProjectID(""), Type{Brand: true}),
+			expected: `
+			export type ProjectID = string & { readonly __brand: "ProjectID" }
+
+			export function toProjectID(value: string): ProjectID {
+				return value as ProjectID
+			}`,
+		},
+		{
+			generated: Convert(UserID{}, Type{Brand: true, Guard: true}),
+			expected: `
+			export type UserID = UuidType & { readonly __brand: "UserID" }
+
+			export function toUserID(value: UuidType): UserID {
+				return value as UserID
+			}
+
+			export function isUserID(x: unknown): x is UserID {
+				return isUuidType(x)
+			}`,
+		},
+		{
+			// without the brand, the named types are plain aliases
+			generated: Convert(ProjectID("")),
+			expected:  `export type ProjectID = string`,
+		},
+	}
+
+	for _, tt := range tests {
+		if stripSpaces(tt.generated) != stripSpaces(tt.expected) {
+			t.Errorf("expected: %v\n, got: %v\n", tt.expected, tt.generated)
+		}
+	}
+}
+
+func TestBrandedRegistryModules(t *testing.T) {
+	brand(t, UserID{})
+
+	reg := NewRegistry().Add(Membership{}, Type{Module: "membership"})
+	out := NewMemFS()
+	if err := reg.GenerateFS(out, Settings{Logger: DiscardLogger}); err != nil {
+		t.Fatal(err)
+	}
+
+	membership, _ := out.ReadFile("membership.ts")
+	if !strings.Contains(string(membership), `import type { UserID } from "./types"`) {
+		t.Errorf("expected the branded type to be imported, got:\n%s", membership)
+	}
+	types, _ := out.ReadFile("types.ts")
+	if !containsAll(string(types), `export type UserID = UuidType & { readonly __brand: "UserID" }`, "export function toUserID(value: UuidType): UserID {") {
+		t.Errorf("expected the branded type in its own module, got:\n%s", types)
+	}
+}
+
+func TestBrandedIndex(t *testing.T) {
+	brand(t, UserID{}, OrgID(0))
+
+	reg := NewRegistry().Add(Membership{}, Type{Guard: true})
+	reg.Index = true
+	out := NewMemFS()
+	if err := reg.GenerateFS(out, Settings{Logger: DiscardLogger}); err != nil {
+		t.Fatal(err)
+	}
+
+	index, _ := out.ReadFile("index.ts")
+	if !containsAll(string(index),
+		`export type { Membership, UserID, OrgID } from "./types"`,
+		`export { isMembership, toUserID, toOrgID } from "./types"`,
+	) {
+		t.Errorf("expected the branded types and their functions to be exported once, got:\n%s", index)
+	}
+}
+
+func TestInvalidBrand(t *testing.T) {
+	tests := []func(){
+		func() { Brand(Membership{}) },
+		func() { Brand("not named") },
+	}
+
+	for i, fn := range tests {
+		func() {
+			defer func() {
+				if err := recover(); err == nil {
+					t.Errorf("%v: expected a panic", i)
+				}
+			}()
+			fn()
+		}()
+	}
+}
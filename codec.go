@@ -0,0 +1,218 @@
+package gut
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tompston/gut/ir"
+)
+
+// codecFunctions returns the decode<Name> and encode<Name> functions of
+// the declaration, which convert the time.Time and int64 / uint64 values
+// between their json representation and the types of the header aliases
+// (e.g. string <-> Date, if DateType = "Date"). The conversion of the
+// values is done by the decode<Alias> / encode<Alias> helpers in the
+// header, which are based on the Settings.
+func (e *tsEmitter) codecFunctions(decl *ir.Decl) string {
+	sb := strings.Builder{}
+	for _, dir := range []string{"decode", "encode"} {
+		c := codec{e: e, dir: dir, visiting: map[*ir.Decl]bool{decl: true}}
+
+		var body string
+		if decl.Kind == ir.AliasDecl {
+			body = c.convert(decl.Type, "v", 0)
+		} else {
+			body = c.object(decl.Fields, "v", 0, "\n    ")
+		}
+		if body == "" {
+			body = "v"
+		}
+
+		if dir == "decode" {
+			sb.WriteString(fmt.Sprintf("export function decode%s(json: unknown): %s {\n", decl.Name, decl.Name))
+			sb.WriteString("  const v = json as any\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("export function encode%s(value: %s): any {\n", decl.Name, decl.Name))
+			sb.WriteString("  const v = value as any\n")
+		}
+		sb.WriteString(fmt.Sprintf("  return %s\n", body))
+		sb.WriteString("}\n\n")
+	}
+	return sb.String()
+}
+
+// codec creates the conversion of the values in a single direction
+type codec struct {
+	e *tsEmitter
+	// "decode" or "encode"
+	dir string
+	// declarations which are currently converted (recursive types)
+	visiting map[*ir.Decl]bool
+}
+
+// convert returns the expression which converts the value of the
+// type. An empty string is returned if the value is not converted.
+func (c codec) convert(typ *ir.Type, value string, depth int) string {
+	switch typ.Kind {
+
+	case ir.Primitive:
+		switch typ.Primitive {
+		case ir.Time:
+			return fmt.Sprintf("%s(%s)", c.e.helper(c.dir+"DateType"), value)
+		case ir.Int64:
+			return fmt.Sprintf("%s(%s)", c.e.helper(c.dir+"BigIntType"), value)
+		}
+
+	case ir.Reference:
+		d := typ.Decl
+		if c.e.settings[d].Codec {
+			c.e.referenced[d] = true
+			return fmt.Sprintf("%s(%s)", c.e.qualified(d, c.dir+d.Name), value)
+		}
+		if c.visiting[d] {
+			return ""
+		}
+		c.visiting[d] = true
+		defer delete(c.visiting, d)
+
+		if d.Kind == ir.AliasDecl {
+			return c.convert(d.Type, value, depth)
+		}
+		return c.nullable(value, c.object(d.Fields, value, depth, " "))
+
+	case ir.Object:
+		return c.nullable(value, c.object(typ.Fields, value, depth, " "))
+
+	case ir.Option:
+		return c.nullable(value, c.convert(typ.Elem, value, depth))
+
+	case ir.Array:
+		elem := fmt.Sprintf("e%d", depth)
+		if conv := c.convert(typ.Elem, elem, depth+1); conv != "" {
+			return c.nullable(value, fmt.Sprintf("%s.map((%s: any) => %s)", value, elem, conv))
+		}
+
+	case ir.Map:
+		elem := fmt.Sprintf("e%d", depth)
+		if conv := c.convert(typ.Elem, elem, depth+1); conv != "" {
+			return c.nullable(value, fmt.Sprintf("Object.fromEntries(Object.entries(%s).map(([k, %s]: [string, any]) => [k, %s]))", value, elem, conv))
+		}
+
+	case ir.Union:
+		return c.union(typ, value, depth)
+	}
+
+	return ""
+}
+
+// object converts the fields of the object, which need to be converted.
+// Other fields are copied. The properties are separated by sep.
+func (c codec) object(fields []*ir.Field, value string, depth int, sep string) string {
+	var converted []string
+	for _, field := range flattenFields(fields) {
+		prop := fmt.Sprintf("%s[%s]", value, quote(field.JSONName))
+
+		var conv string
+		if typ := field.Type.Unwrap(); field.Stringified && typ.Kind == ir.Primitive && typ.Primitive == ir.Int64 {
+			conv = fmt.Sprintf("%s(%s)", c.e.helper(c.dir+"BigIntStringType"), prop)
+			if field.Type.Kind == ir.Option {
+				conv = c.nullable(prop, conv)
+			}
+		} else if !field.Stringified {
+			conv = c.convert(field.Type, prop, depth)
+		}
+
+		if conv == "" {
+			continue
+		}
+		if field.Optional {
+			conv = c.nullable(prop, conv)
+		}
+		converted = append(converted, fmt.Sprintf("%s: %s", quote(field.JSONName), conv))
+	}
+
+	if len(converted) == 0 {
+		return ""
+	}
+	end := strings.TrimSuffix(sep, "  ")
+	return fmt.Sprintf("{%s...%s,%s%s%s}", sep, value, sep, strings.Join(converted, ","+sep), end)
+}
+
+// union converts the variants of a discriminated union, based
+// on the value of the discriminator field.
+func (c codec) union(typ *ir.Type, value string, depth int) string {
+	if typ.Discriminator == "" {
+		return ""
+	}
+
+	sb := strings.Builder{}
+	for _, v := range typ.Variants {
+		if v.Kind != ir.Reference || v.Decl.Kind != ir.StructDecl {
+			continue
+		}
+		field := findField(v.Decl.Fields, typ.Discriminator)
+		conv := c.convert(v, value, depth)
+		if field == nil || field.Type.Kind != ir.Literal || conv == "" {
+			continue
+		}
+		literal, _ := json.Marshal(field.Type.Literal)
+		sb.WriteString(fmt.Sprintf("%s[%s] === %s ? %s : ", value, quote(typ.Discriminator), literal, conv))
+	}
+
+	if sb.Len() == 0 {
+		return ""
+	}
+	return c.nullable(value, fmt.Sprintf("(%s%s)", sb.String(), value))
+}
+
+// nullable skips the conversion of null and undefined values
+func (c codec) nullable(value string, conv string) string {
+	if conv == "" || strings.HasPrefix(conv, fmt.Sprintf("(%s == null ? ", value)) {
+		return conv
+	}
+	return fmt.Sprintf("(%s == null ? %s : %s)", value, value, conv)
+}
+
+// codecHelper returns the decode<Alias> / encode<Alias> helpers, which
+// convert the values between their json representation and the type
+// of the alias.
+func codecHelper(name string, dir string) func(Settings) string {
+	return func(s Settings) string {
+		var conv string
+		switch tsType := aliasType(name, s); {
+		case name == "DateType" && tsType == "Date":
+			conv = map[string]string{
+				"decode": `typeof v === "string" ? new Date(v) : v`,
+				"encode": `v instanceof Date ? v.toISOString() : v`,
+			}[dir]
+		case name == "DateType" && tsType == "number":
+			conv = map[string]string{
+				"decode": `typeof v === "string" ? Date.parse(v) : v`,
+				"encode": `typeof v === "number" ? new Date(v).toISOString() : v`,
+			}[dir]
+		case name == "DateType":
+			conv = "v"
+		case dir == "decode" && tsType == "bigint":
+			conv = `typeof v === "number" || typeof v === "string" ? BigInt(v) : v`
+		case dir == "decode" && tsType == "string":
+			conv = `typeof v === "number" ? String(v) : v`
+		case dir == "decode" && tsType == "number":
+			conv = `typeof v === "string" ? Number(v) : v`
+		case dir == "decode":
+			conv = "v"
+		case name == "BigIntStringType" || int64Mode(s) == Int64String:
+			// the values are marshalled as json strings
+			conv = `typeof v === "bigint" || typeof v === "number" ? String(v) : v`
+		default:
+			// bigints which do not fit into a float64 lose precision,
+			// so the ",string" json option should be used for them
+			conv = `typeof v === "bigint" || typeof v === "string" ? Number(v) : v`
+		}
+
+		if dir == "decode" {
+			return fmt.Sprintf("export function decode%s(v: any): %s {\n  return %s\n}\n", name, qualifiedAlias(name, s), conv)
+		}
+		return fmt.Sprintf("export function encode%s(v: %s): any {\n  return %s\n}\n", name, qualifiedAlias(name, s), conv)
+	}
+}
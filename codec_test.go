@@ -0,0 +1,96 @@
+package gut
+
+import (
+	"testing"
+
+	. "github.com/tompston/gut/types"
+)
+
+func TestCodecConversion(t *testing.T) {
+	generated := Convert(SimpleStructWithTimeFields{}, Type{Codec: true})
+
+	expected := `
+	export interface SimpleStructWithTimeFields {
+		MyString: string
+		CreatedAt: DateType
+		updated_at: DateType
+		deleted_at: DateType
+	}
+
+	export function decodeSimpleStructWithTimeFields(json: unknown): SimpleStructWithTimeFields {
+		const v = json as any
+		return {
+			...v,
+			"CreatedAt": decodeDateType(v["CreatedAt"]),
+			"updated_at": decodeDateType(v["updated_at"]),
+			"deleted_at": decodeDateType(v["deleted_at"])
+		}
+	}
+
+	export function encodeSimpleStructWithTimeFields(value: SimpleStructWithTimeFields): any {
+		const v = value as any
+		return {
+			...v,
+			"CreatedAt": encodeDateType(v["CreatedAt"]),
+			"updated_at": encodeDateType(v["updated_at"]),
+			"deleted_at": encodeDateType(v["deleted_at"])
+		}
+	}`
+
+	if stripSpaces(generated) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+}
+
+func TestCodecNestedValues(t *testing.T) {
+	reg := NewRegistry().
+		Add(StructWithTimestamps{}, Type{Codec: true}).
+		Add(Union[Event](UserCreated{}, UserDeleted{}), Type{Codec: true})
+
+	generated := reg.Convert()
+
+	for _, expected := range []string{
+		`"id": decodeBigIntStringType(v["id"])`,
+		`"counter": decodeBigIntType(v["counter"])`,
+		`"history": (v["history"] == null ? v["history"] : v["history"].map((e0: any) => decodeDateType(e0)))`,
+		`"events": (v["events"] == null ? v["events"] : v["events"].map((e0: any) => encodeEvent(e0)))`,
+		`return (v == null ? v : (v["type"] === "user.created" ? decodeUserCreated(v) : v["type"] === "deleted" ? decodeUserDeleted(v) : v))`,
+	} {
+		if !containsAll(generated, expected) {
+			t.Errorf("expected the output to contain %v, got:\n%v", expected, generated)
+		}
+	}
+}
+
+func TestCodecHelpers(t *testing.T) {
+	content := Convert(StructWithTimestamps{}, Type{Codec: true})
+
+	tests := []struct {
+		settings Settings
+		expected []string
+	}{
+		{
+			settings: Settings{DateType: "Date", Int64Mode: Int64BigInt},
+			expected: []string{
+				"export function decodeDateType(v: any): DateType {\n  return typeof v === \"string\" ? new Date(v) : v\n}",
+				"export function encodeDateType(v: DateType): any {\n  return v instanceof Date ? v.toISOString() : v\n}",
+				"export function decodeBigIntType(v: any): BigIntType {\n  return typeof v === \"number\" || typeof v === \"string\" ? BigInt(v) : v\n}",
+				"export function encodeBigIntStringType(v: BigIntStringType): any {\n  return typeof v === \"bigint\" || typeof v === \"number\" ? String(v) : v\n}",
+			},
+		},
+		{
+			settings: Settings{DateType: "string"},
+			expected: []string{
+				"export function decodeDateType(v: any): DateType {\n  return v\n}",
+				"export function decodeBigIntType(v: any): BigIntType {\n  return typeof v === \"string\" ? Number(v) : v\n}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		header := createHeader(tt.settings, content)
+		if !containsAll(header, tt.expected...) {
+			t.Errorf("expected the header to contain %q, got:\n%v", tt.expected, header)
+		}
+	}
+}
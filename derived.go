@@ -0,0 +1,161 @@
+package gut
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tompston/gut/ir"
+)
+
+// isServerOwned checks if the field is set by the server (`gut:"server"`),
+// so it is omitted from the Create and Update types.
+func isServerOwned(field *ir.Field) bool {
+	_, ok := gutTagOption(field.Tag, "server")
+	return ok
+}
+
+// isReadonly checks if the field can not be changed after it was created
+// (`gut:"readonly"`), so it is omitted from the Update type. The server
+// owned fields are read only too.
+func isReadonly(field *ir.Field) bool {
+	_, ok := gutTagOption(field.Tag, "readonly")
+	return ok || isServerOwned(field)
+}
+
+// marksReadonly checks if the read only fields of the declaration get
+// the readonly modifier, which is done only if the Create or Update
+// types are emitted.
+func marksReadonly(s Type) bool {
+	return s.Create || s.Update
+}
+
+// derivedNames returns the names of the types which are derived from the
+// declaration (Create, Update, Pick and Omit), in the order of emission.
+func derivedNames(decl *ir.Decl, s Type) []string {
+	if decl.Kind != ir.StructDecl {
+		return nil
+	}
+
+	var names []string
+	if s.Create {
+		names = append(names, decl.Name+"Create")
+	}
+	if s.Update {
+		names = append(names, decl.Name+"Update")
+	}
+	names = append(names, sortedKeys(s.Pick)...)
+	return append(names, sortedKeys(s.Omit)...)
+}
+
+// checkDerived returns an error if the names of the derived types are not
+// valid, or if the Pick / Omit projections hold fields which do not exist.
+func checkDerived(decl *ir.Decl, s Type) error {
+	fields := make(map[string]bool)
+	for _, name := range jsonNames(decl.Fields) {
+		fields[name] = true
+	}
+
+	for _, name := range derivedNames(decl, s) {
+		if !isValidTypeName(name) {
+			return fmt.Errorf("gut: %q (derived from %v) is not a valid typescript name", name, decl.Name)
+		}
+		projected, ok := s.Pick[name]
+		if omitted, isOmit := s.Omit[name]; isOmit {
+			projected, ok = omitted, true
+		}
+		if ok && len(projected) == 0 {
+			return fmt.Errorf("gut: %v does not list any fields of %v", name, decl.Name)
+		}
+		for _, f := range projected {
+			if !fields[f] {
+				return fmt.Errorf("gut: %v does not have the field %q (used by %v)", decl.Name, f, name)
+			}
+		}
+	}
+	return nil
+}
+
+// derivedTypes returns the types which are derived from the fields of the
+// declaration: <Name>Create without the server owned fields, <Name>Update
+// in which the fields which can be changed are (deeply) optional, and the
+// Pick / Omit projections.
+func (e *tsEmitter) derivedTypes(decl *ir.Decl) string {
+	s := e.settings[decl]
+	if decl.Kind != ir.StructDecl {
+		return ""
+	}
+
+	sb := strings.Builder{}
+	if s.Create {
+		sb.WriteString(fmt.Sprintf("export interface %sCreate {\n", decl.Name))
+		sb.WriteString(e.derivedFields(decl.Fields, func(f *ir.Field) bool { return !isServerOwned(f) }, false))
+		sb.WriteString("}\n\n")
+	}
+	if s.Update {
+		sb.WriteString(fmt.Sprintf("export interface %sUpdate {\n", decl.Name))
+		sb.WriteString(e.derivedFields(decl.Fields, func(f *ir.Field) bool { return !isReadonly(f) }, true))
+		sb.WriteString("}\n\n")
+	}
+	for _, name := range sortedKeys(s.Pick) {
+		sb.WriteString(fmt.Sprintf("export type %s = Pick<%s, %s>\n\n", name, decl.Name, quotedUnion(s.Pick[name])))
+	}
+	for _, name := range sortedKeys(s.Omit) {
+		sb.WriteString(fmt.Sprintf("export type %s = Omit<%s, %s>\n\n", name, decl.Name, quotedUnion(s.Omit[name])))
+	}
+	return sb.String()
+}
+
+// derivedFields converts the fields (including the fields of the inlined
+// structs) which are kept. If partial is set, every field is optional and
+// the nested structs are wrapped in DeepPartial.
+func (e *tsEmitter) derivedFields(fields []*ir.Field, keep func(*ir.Field) bool, partial bool) string {
+	sb := strings.Builder{}
+	for _, field := range fields {
+		if field.Inline {
+			sb.WriteString(e.derivedFields(field.Type.Fields, keep, partial))
+			continue
+		}
+		if !keep(field) {
+			continue
+		}
+
+		e.indent = "  "
+		name, typ := typescriptFieldname(field), e.fieldTS(field)
+		if partial {
+			name = field.JSONName + "?"
+			if isStructType(field.Type) {
+				typ = fmt.Sprintf("%s<%s>", e.helper("DeepPartial"), typ)
+			}
+		}
+		sb.WriteString(fmt.Sprintf("  %s: %s\n", name, typ))
+	}
+	return sb.String()
+}
+
+// isStructType checks if the type is an (optional) object
+// or a reference to the declaration of a struct.
+func isStructType(typ *ir.Type) bool {
+	typ = typ.Unwrap()
+	return typ.Kind == ir.Object || typ.Kind == ir.Reference && typ.Decl.Kind == ir.StructDecl
+}
+
+// deepPartialHelper returns the DeepPartial type, which makes the
+// properties of the nested objects optional. Arrays are replaced as
+// a whole, so their elements are not partial.
+func deepPartialHelper(Settings) string {
+	return `export type DeepPartial<T> = T extends Date | ((...args: any[]) => any) | any[]
+  ? T
+  : T extends object
+    ? { [K in keyof T]?: DeepPartial<T[K]> }
+    : T
+`
+}
+
+// quotedUnion returns the union of the quoted names ("id" | "name")
+func quotedUnion(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, fmt.Sprintf("%q", name))
+	}
+	return strings.Join(quoted, " | ")
+}
@@ -0,0 +1,124 @@
+package gut
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/tompston/gut/types"
+)
+
+func TestDerivedTypes(t *testing.T) {
+	generated := Convert(Account{}, Type{
+		Create: true,
+		Update: true,
+		Pick:   map[string][]string{"AccountSummary": {"id", "name"}},
+		Omit:   map[string][]string{"PublicAccount": {"password"}},
+	})
+
+	expected := `
+	export interface Account {
+		readonly id: BigIntStringType
+		readonly created_at: DateType
+		readonly email: string
+		name: string
+		password?: string
+		profile: {
+			bio: string
+			website: string
+		}
+		settings?: {
+			theme: string
+		}
+		tags: string[]
+	}
+
+	export interface AccountCreate {
+		email: string
+		name: string
+		password?: string
+		profile: {
+			bio: string
+			website: string
+		}
+		settings?: {
+			theme: string
+		}
+		tags: string[]
+	}
+
+	export interface AccountUpdate {
+		name?: string
+		password?: string
+		profile?: DeepPartial<{
+			bio: string
+			website: string
+		}>
+		settings?: DeepPartial<{
+			theme: string
+		}>
+		tags?: string[]
+	}
+
+	export type AccountSummary = Pick<Account, "id" | "name">
+
+	export type PublicAccount = Omit<Account, "password">`
+
+	if stripSpaces(generated) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+}
+
+func TestReadonlyFields(t *testing.T) {
+	// the fields are marked as readonly only if the derived types are emitted
+	for _, settings := range []Type{{}, {Pick: map[string][]string{"AccountSummary": {"id"}}}} {
+		if generated := Convert(Account{}, settings); strings.Contains(generated, "readonly") {
+			t.Errorf("expected no readonly properties, got:\n%v", generated)
+		}
+	}
+
+	if generated := Convert(Account{}, Type{Create: true}); !containsAll(generated, "  readonly id: BigIntStringType\n", "  readonly email: string\n", "  name: string\n") {
+		t.Errorf("expected readonly properties, got:\n%v", generated)
+	}
+}
+
+func TestDerivedTypesOfReferences(t *testing.T) {
+	reg := NewRegistry().Add(Account{}, Type{Update: true}).Add(Profile{})
+	generated := reg.Convert()
+
+	if !containsAll(generated, "profile?: DeepPartial<Profile>", "export interface Profile {") {
+		t.Errorf("expected the reference to be deeply partial, got:\n%v", generated)
+	}
+
+	out := NewMemFS()
+	if err := reg.GenerateFS(out, Settings{Logger: DiscardLogger}); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := out.ReadFile("types.ts")
+	if !strings.Contains(string(content), "export type DeepPartial<T> =") {
+		t.Errorf("expected the DeepPartial helper in the header, got:\n%s", content)
+	}
+}
+
+func TestInvalidDerivedTypes(t *testing.T) {
+	tests := []struct {
+		settings Type
+		err      string
+	}{
+		{Type{Pick: map[string][]string{"AccountSummary": {"id", "missing"}}}, `Account does not have the field "missing"`},
+		{Type{Omit: map[string][]string{"PublicAccount": nil}}, "PublicAccount does not list any fields"},
+		{Type{Pick: map[string][]string{"account-summary": {"id"}}}, "is not a valid typescript name"},
+		{Type{Name: "Account", Pick: map[string][]string{"AccountCreate": {"id"}}, Create: true}, "AccountCreate (derived from Account) is declared more than once"},
+	}
+
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if err := recover(); err == nil || !strings.Contains(fmt.Sprint(err), tt.err) {
+					t.Errorf("expected %q, got %v", tt.err, err)
+				}
+			}()
+			Convert(Account{}, tt.settings)
+		}()
+	}
+}
@@ -0,0 +1,237 @@
+package gut
+
+import (
+	"fmt"
+	"strings"
+)
+
+// number of unchanged lines which are shown around every change
+const diffContext = 3
+
+type diffOp struct {
+	kind byte // ' ' for equal lines, '-' for deleted and '+' for inserted lines
+	text string
+}
+
+// unifiedDiff returns the unified diff between the current and the
+// wanted content of the file. An empty string is returned if both
+// of them are equal.
+func unifiedDiff(filename string, current string, wanted string) string {
+	if current == wanted {
+		return ""
+	}
+
+	ops := diffLines(splitLines(current), splitLines(wanted))
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s (generated)\n", filename, filename))
+
+	// line numbers (0 based) of the current op in both of the files
+	lineA, lineB := 0, 0
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			lineA++
+			lineB++
+			i++
+			continue
+		}
+
+		// extend the hunk until there are no changes in the next
+		// 2*diffContext lines, so that close changes are merged
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && next-end < 2*diffContext && ops[next].kind == ' ' {
+				next++
+			}
+			if next < len(ops) && ops[next].kind != ' ' {
+				end = next
+				continue
+			}
+			break
+		}
+		stop := end + diffContext
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+
+		hunkA, hunkB := lineA-(i-start), lineB-(i-start)
+		lenA, lenB := 0, 0
+		for _, op := range ops[start:stop] {
+			if op.kind != '+' {
+				lenA++
+			}
+			if op.kind != '-' {
+				lenB++
+			}
+		}
+
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(hunkA, lenA), hunkRange(hunkB, lenB)))
+		for _, op := range ops[start:stop] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.text)
+			sb.WriteByte('\n')
+		}
+
+		for _, op := range ops[i:stop] {
+			if op.kind != '+' {
+				lineA++
+			}
+			if op.kind != '-' {
+				lineB++
+			}
+		}
+		i = stop
+	}
+
+	return sb.String()
+}
+
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// splitLines splits the content into lines. If the content does not end
+// with a newline, the marker of the unified diff is added to the last line,
+// so that it differs from the same line which ends with a newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	if !strings.HasSuffix(s, "\n") {
+		lines[len(lines)-1] += "\n\\ No newline at end of file"
+	}
+	return lines
+}
+
+// maximum number of edits which are searched for. The trace of the search
+// grows with the square of the edits, so the files which differ more are
+// diffed by replacing all of the changed lines.
+const diffMaxEdits = 1024
+
+// diffLines returns the edit script which transforms the lines of a into
+// the lines of b. The common prefix and suffix are kept and the lines
+// between them are diffed with the Myers diff algorithm.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// myersDiff returns the shortest edit script which transforms the lines
+// of a into the lines of b (or replaces all of the lines, if it needs more
+// than diffMaxEdits edits). Only the diagonals which can be reached with d
+// edits (-d-1 ... d+1) are stored in the trace.
+func myersDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+
+	v := make([]int, 2*max+3)
+	var trace [][]int
+
+search:
+	for d := 0; d <= max; d++ {
+		if d > diffMaxEdits {
+			return replaceLines(a, b)
+		}
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// walk back through the trace in order to find the edits
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		// the diagonal k is stored at k+d+1
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[k-1+d+1] < v[k+1+d+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+d+1]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{'+', b[y-1]})
+			} else {
+				ops = append(ops, diffOp{'-', a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// replaceLines deletes all of the lines of a and inserts the lines of b
+func replaceLines(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a {
+		ops = append(ops, diffOp{'-', line})
+	}
+	for _, line := range b {
+		ops = append(ops, diffOp{'+', line})
+	}
+	return ops
+}
@@ -0,0 +1,112 @@
+package gut
+
+import "fmt"
+
+// DurationFormat defines how the time.Duration values are marshalled to
+// json, which determines the type of the DurationType alias and the
+// implementation of the parseDuration and formatDuration helpers.
+type DurationFormat string
+
+const (
+	// Integer number of nanoseconds, which is how
+	// encoding/json marshals time.Duration (DurationType = number)
+	DurationNanoseconds DurationFormat = "nanoseconds"
+	// Floating point number of seconds (DurationType = number)
+	DurationSeconds DurationFormat = "seconds"
+	// ISO-8601 duration string, like "PT1H30M" (DurationType = string)
+	DurationISO8601 DurationFormat = "iso8601"
+	// Go duration string, like "1h30m0s", which is returned
+	// by time.Duration.String() (DurationType = string)
+	DurationString DurationFormat = "string"
+)
+
+func durationFormat(s Settings) DurationFormat {
+	switch s.DurationFormat {
+	case DurationNanoseconds, DurationSeconds, DurationISO8601, DurationString:
+		return s.DurationFormat
+	case "":
+		return DurationNanoseconds
+	default:
+		panic(fmt.Sprintf("Invalid duration format was provided! %v", s.DurationFormat))
+	}
+}
+
+func (f DurationFormat) tsType() string {
+	if f == DurationISO8601 || f == DurationString {
+		return "string"
+	}
+	return "number"
+}
+
+// parseDurationHelper returns a function which converts
+// the DurationType value into milliseconds.
+func parseDurationHelper(s Settings) string {
+	signature := fmt.Sprintf("export function parseDuration(value: %s): number {\n", qualifiedAlias("DurationType", s))
+
+	switch durationFormat(s) {
+	case DurationSeconds:
+		return signature + "  return value * 1000\n}\n"
+
+	case DurationISO8601:
+		return signature + `  const match = /^(-)?P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$/.exec(value)
+  if (!match) throw new Error("invalid ISO-8601 duration: " + value)
+  const [, sign, d, h, m, sec] = match
+  const ms = (Number(d ?? 0) * 86400 + Number(h ?? 0) * 3600 + Number(m ?? 0) * 60 + Number(sec ?? 0)) * 1000
+  return sign ? -ms : ms
+}
+`
+
+	case DurationString:
+		return signature + `  const units: { [unit: string]: number } = { ns: 1e-6, us: 1e-3, "µs": 1e-3, ms: 1, s: 1000, m: 60000, h: 3600000 }
+  const match = /^([-+])?((?:\d+(?:\.\d*)?|\.\d+)(?:ns|us|µs|ms|s|m|h))+$/.exec(value)
+  if (!match && value !== "0") throw new Error("invalid duration: " + value)
+  let ms = 0
+  for (const [, amount, unit] of value.matchAll(/(\d+(?:\.\d*)?|\.\d+)(ns|us|µs|ms|s|m|h)/g)) {
+    ms += Number(amount) * units[unit]
+  }
+  return value.startsWith("-") ? -ms : ms
+}
+`
+
+	default:
+		return signature + "  return value / 1e6\n}\n"
+	}
+}
+
+// formatDurationHelper returns a function which converts
+// milliseconds into the DurationType value.
+func formatDurationHelper(s Settings) string {
+	signature := fmt.Sprintf("export function formatDuration(ms: number): %s {\n", qualifiedAlias("DurationType", s))
+
+	switch durationFormat(s) {
+	case DurationSeconds:
+		return signature + "  return ms / 1000\n}\n"
+
+	case DurationISO8601:
+		return signature + `  const sign = ms < 0 ? "-" : ""
+  let rest = Math.abs(ms)
+  const h = Math.floor(rest / 3600000)
+  rest -= h * 3600000
+  const m = Math.floor(rest / 60000)
+  rest -= m * 60000
+  return sign + "PT" + (h ? h + "H" : "") + (m ? m + "M" : "") + (rest || !(h || m) ? rest / 1000 + "S" : "")
+}
+`
+
+	case DurationString:
+		return signature + `  if (ms === 0) return "0s"
+  const sign = ms < 0 ? "-" : ""
+  let rest = Math.abs(ms)
+  if (rest < 1000) return sign + rest + "ms"
+  const h = Math.floor(rest / 3600000)
+  rest -= h * 3600000
+  const m = Math.floor(rest / 60000)
+  rest -= m * 60000
+  return sign + (h ? h + "h" : "") + (h || m ? m + "m" : "") + rest / 1000 + "s"
+}
+`
+
+	default:
+		return signature + "  return Math.round(ms * 1e6)\n}\n"
+	}
+}
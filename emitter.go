@@ -0,0 +1,135 @@
+package gut
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tompston/gut/ir"
+)
+
+// Emitter converts the declarations of the ir.Graph into the output of a
+// single file. For every declaration, EmitField is called for each of
+// its fields and the results are passed to EmitDecl. Footer and Header
+// are called (in this order) after all of the declarations were emitted,
+// so that the header can depend on them and on the footer, and their
+// output is added around them.
+type Emitter interface {
+	Header(g *ir.Graph) string
+	EmitDecl(d *ir.Decl, fields []string) string
+	EmitField(d *ir.Decl, f *ir.Field) string
+	Footer(g *ir.Graph) string
+}
+
+// EmitterFactory creates a new Emitter for a single output.
+type EmitterFactory func(s Settings) Emitter
+
+var (
+	emitterMu sync.RWMutex
+	emitters  = map[string]EmitterFactory{
+		"typescript":   newTypescriptEmitter,
+		"jsonschema":   newJSONSchemaEmitter,
+		"openapi":      newOpenAPIEmitter,
+		"openapi-yaml": newOpenAPIYAMLEmitter,
+	}
+)
+
+// RegisterEmitter registers the emitter under the name, so that it can
+// be selected with Emit, Registry.Emit and Registry.Outputs. The builtin
+// emitters are "typescript", "jsonschema", "openapi" and "openapi-yaml".
+//
+// Example
+//
+//	gut.RegisterEmitter("markdown", func(s gut.Settings) gut.Emitter { return &MarkdownEmitter{} })
+func RegisterEmitter(name string, factory EmitterFactory) {
+	if name == "" || factory == nil {
+		panic("The name and the factory of the emitter cannot be empty!")
+	}
+
+	emitterMu.Lock()
+	defer emitterMu.Unlock()
+	emitters[name] = factory
+}
+
+// Emitters returns the names of the registered emitters.
+func Emitters() []string {
+	emitterMu.RLock()
+	defer emitterMu.RUnlock()
+
+	names := make([]string, 0, len(emitters))
+	for name := range emitters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newEmitter(name string, s Settings) (Emitter, error) {
+	emitterMu.RLock()
+	factory, ok := emitters[name]
+	emitterMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("gut: unknown emitter %q", name)
+	}
+	return factory(s), nil
+}
+
+// Emit converts all of the declarations of the graph with the emitter
+// which is registered under the name.
+//
+// Example
+//
+//	graph, _ := ir.FromValues(User{}, Comment{})
+//	schema, err := gut.Emit("jsonschema", graph)
+func Emit(name string, graph *ir.Graph, settings ...Settings) (string, error) {
+	em, err := newEmitter(name, getSettings(settings))
+	if err != nil {
+		return "", err
+	}
+	if ts, ok := em.(*typescriptEmitter); ok {
+		ts.graph = graph
+	}
+	return emit(em, graph, graph.Decls), nil
+}
+
+// emit converts the declarations (in the passed in order) with the emitter
+func emit(em Emitter, graph *ir.Graph, decls []*ir.Decl) string {
+	body := strings.Builder{}
+	for _, d := range decls {
+		body.WriteString(emitDecl(em, d))
+	}
+	footer := em.Footer(graph)
+	return em.Header(graph) + body.String() + footer
+}
+
+func emitDecl(em Emitter, d *ir.Decl) string {
+	fields := make([]string, 0, len(d.Fields))
+	for _, f := range d.Fields {
+		fields = append(fields, em.EmitField(d, f))
+	}
+	return em.EmitDecl(d, fields)
+}
+
+// typescriptEmitter emits a single typescript file, with the header of
+// the used aliases, like the Generate function.
+type typescriptEmitter struct {
+	*tsEmitter
+	s Settings
+}
+
+func newTypescriptEmitter(s Settings) Emitter {
+	e := &typescriptEmitter{tsEmitter: newTSEmitter(nil, nil), s: s}
+	e.qualifier = aliasQualifier(s)
+	return e
+}
+
+func (e *typescriptEmitter) Header(*ir.Graph) string {
+	return usedHeader(e.s, e.used, false)
+}
+
+// Footer returns the clients of the endpoints and of the services of the graph
+func (e *typescriptEmitter) Footer(g *ir.Graph) string {
+	return e.client(g)
+}
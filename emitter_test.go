@@ -0,0 +1,142 @@
+package gut
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tompston/gut/ir"
+	. "github.com/tompston/gut/types"
+)
+
+// fieldListEmitter lists the json names of the fields of every declaration
+type fieldListEmitter struct{}
+
+func (fieldListEmitter) Header(g *ir.Graph) string { return fmt.Sprintf("# %v types\n", len(g.Decls)) }
+func (fieldListEmitter) Footer(*ir.Graph) string   { return "# end\n" }
+
+func (fieldListEmitter) EmitDecl(d *ir.Decl, fields []string) string {
+	return fmt.Sprintf("%s: %s\n", d.Name, strings.Join(fields, ", "))
+}
+
+func (fieldListEmitter) EmitField(d *ir.Decl, f *ir.Field) string {
+	return f.JSONName
+}
+
+func TestCustomEmitter(t *testing.T) {
+	RegisterEmitter("fields", func(Settings) Emitter { return fieldListEmitter{} })
+
+	reg := NewRegistry().Add(StructWithReference{}).Add(ReferenceStruct{})
+
+	generated, err := reg.Emit("fields")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# 2 types\nStructWithReference: my_str, MyInt, ref, opt_ref\nReferenceStruct: my_float, timestamp\n# end\n"
+	if generated != expected {
+		t.Fatalf("expected: %q\n, got: %q\n", expected, generated)
+	}
+
+	if _, err := reg.Emit("unknown"); err == nil {
+		t.Errorf("expected an error for an unknown emitter")
+	}
+}
+
+func TestTypescriptEmitter(t *testing.T) {
+	graph, err := ir.FromValues(StructWithTimeDurationField{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generated, err := Emit("typescript", graph, Settings{AliasPrefix: "Api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+	export type ApiDurationType = number
+
+	export interface StructWithTimeDurationField {
+		SomeValue: string
+		CurrentTime: ApiDurationType
+	}`
+
+	if stripSpaces(generated) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+}
+
+func TestTypescriptEmitterClient(t *testing.T) {
+	reg := NewRegistry().Add(Endpoint{Path: "/users/{id}", Response: SimpleStructWithTimeFields{}})
+
+	generated, err := reg.Emit("typescript", Settings{AliasPrefix: "Api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the helpers which are used by the client are declared in the header
+	for _, expected := range []string{
+		"export type ApiDateType = Date\n",
+		"export function decodeDateType(v: any): ApiDateType {\n",
+		"CreatedAt: ApiDateType\n",
+		"\"CreatedAt\": decodeDateType(v[\"CreatedAt\"])",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Fatalf("expected the output to contain %q, got:\n%v", expected, generated)
+		}
+	}
+}
+
+func TestJSONSchemaEmitter(t *testing.T) {
+	reg := NewRegistry().Add(StructWithReference{}).Add(ReferenceStruct{})
+	reg.Outputs = map[string]string{"schema.json": "jsonschema"}
+
+	out := NewMemFS()
+	if err := reg.GenerateFS(out, Settings{Logger: DiscardLogger}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := out.ReadFile("schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]map[string]interface{} `json:"properties"`
+			Required   []string                          `json:"required"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(content, &schema); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, content)
+	}
+
+	def := schema.Defs["StructWithReference"]
+	if fmt.Sprint(def.Required) != "[my_str MyInt ref opt_ref]" {
+		t.Errorf("unexpected required fields %v", def.Required)
+	}
+	if def.Properties["opt_ref"]["$ref"] != "#/$defs/ReferenceStruct" {
+		t.Errorf("unexpected reference %v", def.Properties["opt_ref"])
+	}
+	if schema.Defs["ReferenceStruct"].Properties["timestamp"]["type"] != "integer" {
+		t.Errorf("unexpected schema of ReferenceStruct %v", schema.Defs["ReferenceStruct"])
+	}
+
+	// the byte slices are marshalled as base64 strings
+	graph, err := ir.FromValues(StructWithBytes{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bytesSchema, err := Emit("jsonschema", graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(bytesSchema), &schema); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, bytesSchema)
+	}
+	if data := schema.Defs["StructWithBytes"].Properties["data"]; data["type"] != "string" || data["format"] != "byte" {
+		t.Errorf("expected the bytes to be a base64 string, got %v", data)
+	}
+}
@@ -0,0 +1,274 @@
+package gut
+
+import (
+	"fmt"
+	r "reflect"
+	"regexp"
+	"strings"
+
+	"github.com/tompston/gut/ir"
+)
+
+// Endpoint describes a HTTP endpoint, which is added to a Registry. The
+// registry emits a createClient function, which returns a typed fetch
+// client with a function for every endpoint.
+//
+// Example
+//
+//	reg.Add(gut.Endpoint{Method: "GET", Path: "/users/{id}", Response: User{}})
+//	// const api = createClient({ baseUrl: "/api" })
+//	// const user = await api.getUsersById({ id: 1 })
+type Endpoint struct {
+	// Optional name of the client function. (Default = method + path,
+	// e.g. GET /users/{id} -> getUsersById)
+	Name string
+	// HTTP method of the endpoint. (Default = "GET")
+	Method string
+	// Path of the endpoint. Path parameters are written as {name} or :name
+	Path string
+	// Optional value of the json body of the request
+	Request interface{}
+	// Optional value of the json response. If nil, the function returns Promise<void>
+	Response interface{}
+	// Optional struct, whose fields are serialized as the query parameters
+	Query interface{}
+}
+
+var (
+	httpMethods  = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+	pathParamExp = regexp.MustCompile(`\{(\w+)\}|:(\w+)`)
+	// client functions are camelCase identifiers
+	clientNameExp = regexp.MustCompile(`^[a-z_$][0-9a-zA-Z_$]*$`)
+)
+
+// normalize validates the endpoint and fills in the defaults
+func (ep Endpoint) normalize() Endpoint {
+	if ep.Method == "" {
+		ep.Method = "GET"
+	}
+	ep.Method = strings.ToUpper(ep.Method)
+
+	valid := false
+	for _, m := range httpMethods {
+		if ep.Method == m {
+			valid = true
+		}
+	}
+	if !valid {
+		panic(fmt.Sprintf("Invalid HTTP method was provided! %v", ep.Method))
+	}
+
+	if !strings.HasPrefix(ep.Path, "/") {
+		panic(fmt.Sprintf("The path of the endpoint has to start with a slash! %v", ep.Path))
+	}
+
+	if ep.Name == "" {
+		ep.Name = endpointName(ep.Method, ep.Path)
+	}
+	if !clientNameExp.MatchString(ep.Name) || !isValidTypeName(ep.Name) {
+		panic(fmt.Sprintf("Invalid client function name was provided! %v", ep.Name))
+	}
+
+	return ep
+}
+
+// endpointName returns the name of the client function,
+// created from the method and the path of the endpoint.
+func endpointName(method, path string) string {
+	words := []string{strings.ToLower(method)}
+	for _, segment := range strings.Split(path, "/") {
+		if m := pathParamExp.FindStringSubmatch(segment); m != nil {
+			words = append(words, "by", m[1]+m[2])
+		} else {
+			words = append(words, segment)
+		}
+	}
+	return camelCase(strings.Join(words, "_"))
+}
+
+// pathParams returns the names of the path parameters
+func pathParams(path string) []string {
+	var params []string
+	for _, m := range pathParamExp.FindAllStringSubmatch(path, -1) {
+		params = append(params, m[1]+m[2])
+	}
+	return params
+}
+
+// declareEndpointTypes declares the named structs which are used by the
+// endpoint (if they are not declared yet), so that they are referenced
+// by the client, instead of being inlined.
+func declareEndpointTypes(b *ir.Builder, ep Endpoint) []*ir.Decl {
+	return declareNamedStructs(b, r.TypeOf(ep.Request), r.TypeOf(ep.Response), r.TypeOf(ep.Query))
+}
+
+// declareNamedStructs declares the named structs (or the elements of the
+// pointers, slices, arrays and maps), which are not declared yet.
+func declareNamedStructs(b *ir.Builder, types ...r.Type) []*ir.Decl {
+	var decls []*ir.Decl
+	for _, t := range types {
+		for t != nil && (t.Kind() == r.Ptr || t.Kind() == r.Slice || t.Kind() == r.Array || t.Kind() == r.Map) {
+			t = t.Elem()
+		}
+		if t != nil && t.Kind() == r.Struct && t.Name() != "" && b.Decl(t) == nil {
+			if _, ok := registeredAlias(t); !ok {
+				decls = append(decls, b.Declare(t, ir.TypeName(t)))
+			}
+		}
+	}
+	return decls
+}
+
+// addEndpoint converts the types of the endpoint and adds it to the graph
+func addEndpoint(b *ir.Builder, ep Endpoint) {
+	built := &ir.Endpoint{Name: ep.Name, Method: ep.Method, Path: ep.Path}
+	if ep.Request != nil {
+		built.Request = b.Type(r.TypeOf(ep.Request))
+	}
+	if ep.Response != nil {
+		built.Response = b.Type(r.TypeOf(ep.Response))
+	}
+	if ep.Query != nil {
+		built.Query = b.Type(r.TypeOf(ep.Query))
+	}
+	b.AddEndpoint(built)
+}
+
+// client returns the typescript clients of the endpoints
+// and of the services of the graph.
+func (e *tsEmitter) client(g *ir.Graph) string {
+	sb := strings.Builder{}
+	if len(g.Endpoints) > 0 {
+		sb.WriteString(e.clientFunctions(g.Endpoints))
+	}
+	if len(g.Services) > 0 {
+		sb.WriteString(e.serviceClients(g.Services))
+	}
+	return sb.String()
+}
+
+// clientFunctions returns the typescript client with
+// a function for every endpoint.
+func (e *tsEmitter) clientFunctions(endpoints []*ir.Endpoint) string {
+	sb := strings.Builder{}
+	sb.WriteString(clientPrelude)
+
+	for _, ep := range endpoints {
+		var params []string
+		for _, p := range pathParams(ep.Path) {
+			params = append(params, fmt.Sprintf("%s: string | number", p))
+		}
+		if ep.Query != nil {
+			params = append(params, fmt.Sprintf("query?: %s", e.toTS(ep.Query)))
+		}
+		if ep.Request != nil {
+			params = append(params, fmt.Sprintf("body: %s", e.toTS(ep.Request)))
+		}
+
+		args := "init?: RequestInit"
+		if len(params) > 0 {
+			// the params can be omitted, if they only hold the query
+			optional := ""
+			if len(params) == 1 && ep.Query != nil {
+				optional = " = {}"
+			}
+			args = fmt.Sprintf("params: { %s }%s, %s", strings.Join(params, ", "), optional, args)
+		}
+
+		path := pathParamExp.ReplaceAllStringFunc(ep.Path, func(param string) string {
+			name := strings.Trim(param, "{}:")
+			return fmt.Sprintf("${encodeURIComponent(String(params.%s))}", name)
+		})
+
+		query, body := "undefined", "undefined"
+		if ep.Query != nil {
+			query = "params.query"
+		}
+		if ep.Request != nil {
+			body = "params.body"
+			if conv := (codec{e: e, dir: "encode", visiting: map[*ir.Decl]bool{}}).convert(ep.Request, "params.body", 0); conv != "" {
+				body = conv
+			}
+		}
+
+		response, then := "void", ""
+		if ep.Response != nil {
+			response = e.toTS(ep.Response)
+			if conv := (codec{e: e, dir: "decode", visiting: map[*ir.Decl]bool{}}).convert(ep.Response, "v", 0); conv != "" {
+				then = fmt.Sprintf(".then((v: any) => %s)", conv)
+			}
+		}
+
+		sb.WriteString(fmt.Sprintf("    %s: (%s): Promise<%s> =>\n", ep.Name, args, response))
+		sb.WriteString(fmt.Sprintf("      request<%s>(%q, `%s`, %s, %s, init)%s,\n", response, ep.Method, path, query, body, then))
+	}
+
+	sb.WriteString("  }\n}\n\n")
+	return sb.String()
+}
+
+// valueImports returns the functions (type guards, decoders and encoders)
+// of the referenced declarations from other modules, which are called in
+// the content, grouped by their module.
+func valueImports(content string, name string, referenced map[*ir.Decl]bool, modules map[*ir.Decl]string) map[string][]string {
+	imports := make(map[string][]string)
+	for d := range referenced {
+		if modules[d] == name {
+			continue
+		}
+		for _, fn := range []string{"is" + d.Name, "decode" + d.Name, "encode" + d.Name} {
+			if strings.Contains(content, fn+"(") {
+				imports[modules[d]] = append(imports[modules[d]], fn)
+			}
+		}
+	}
+	return imports
+}
+
+const clientPrelude = `export class ApiError extends Error {
+  status: number
+  body: string
+
+  constructor(status: number, body: string) {
+    super(` + "`request failed with status ${status}`" + `)
+    this.status = status
+    this.body = body
+  }
+}
+
+export interface ClientOptions {
+  // prefix of the paths of the endpoints (e.g. "https://example.com/api")
+  baseUrl?: string
+  // headers which are sent with every request
+  headers?: Record<string, string>
+  // custom implementation of fetch
+  fetch?: typeof fetch
+}
+
+export function createClient(options: ClientOptions = {}) {
+  const doFetch = options.fetch ?? fetch
+
+  async function request<T>(method: string, path: string, query: object | undefined, body: unknown, init?: RequestInit): Promise<T> {
+    let url = (options.baseUrl ?? "") + path
+    if (query) {
+      const params = new URLSearchParams()
+      for (const [key, value] of Object.entries(query)) {
+        for (const v of Array.isArray(value) ? value : [value]) {
+          if (v !== undefined && v !== null) params.append(key, v instanceof Date ? v.toISOString() : String(v))
+        }
+      }
+      if (params.toString()) url += "?" + params.toString()
+    }
+
+    const headers: Record<string, string> = { ...options.headers, ...(init?.headers as Record<string, string>) }
+    if (body !== undefined) headers["Content-Type"] = "application/json"
+
+    const res = await doFetch(url, { ...init, method, headers, body: body === undefined ? undefined : JSON.stringify(body) })
+    if (!res.ok) throw new ApiError(res.status, await res.text())
+
+    const text = await res.text()
+    return (text ? JSON.parse(text) : undefined) as T
+  }
+
+  return {
+`
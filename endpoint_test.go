@@ -0,0 +1,109 @@
+package gut
+
+import (
+	"testing"
+
+	. "github.com/tompston/gut/types"
+)
+
+func TestEndpointName(t *testing.T) {
+	tests := []struct {
+		method, path, expected string
+	}{
+		{"GET", "/users/{id}", "getUsersById"},
+		{"POST", "/users", "postUsers"},
+		{"DELETE", "/teams/:team_id/members/{user_id}", "deleteTeamsByTeamIdMembersByUserId"},
+		{"GET", "/", "get"},
+	}
+
+	for _, tt := range tests {
+		if got := endpointName(tt.method, tt.path); got != tt.expected {
+			t.Errorf("endpointName(%q, %q) = %q, expected %q", tt.method, tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestEndpointClient(t *testing.T) {
+	reg := NewRegistry().
+		Add(SimpleStructWithTimeFields{}, Type{Codec: true}).
+		Add(Endpoint{Path: "/users/{id}", Response: SimpleStructWithTimeFields{}}).
+		Add(Endpoint{Path: "/users", Query: ListUsersQuery{}, Response: []ReferenceStruct{}}).
+		Add(Endpoint{Method: "post", Path: "/users", Request: CreateUserRequest{}}).
+		Add(Endpoint{Name: "deleteUser", Method: "DELETE", Path: "/users/:id"})
+
+	generated := reg.Convert()
+
+	for _, expected := range []string{
+		"export interface ListUsersQuery {",
+		"export interface CreateUserRequest {",
+		"export function createClient(options: ClientOptions = {}) {",
+		"getUsersById: (params: { id: string | number }, init?: RequestInit): Promise<SimpleStructWithTimeFields> =>\n" +
+			"      request<SimpleStructWithTimeFields>(\"GET\", `/users/${encodeURIComponent(String(params.id))}`, undefined, undefined, init).then((v: any) => decodeSimpleStructWithTimeFields(v)),",
+		"getUsers: (params: { query?: ListUsersQuery } = {}, init?: RequestInit): Promise<ReferenceStruct[]> =>\n" +
+			"      request<ReferenceStruct[]>(\"GET\", `/users`, params.query, undefined, init),",
+		"postUsers: (params: { body: CreateUserRequest }, init?: RequestInit): Promise<void> =>\n" +
+			"      request<void>(\"POST\", `/users`, undefined, params.body, init),",
+		"deleteUser: (params: { id: string | number }, init?: RequestInit): Promise<void> =>",
+	} {
+		if !containsAll(generated, expected) {
+			t.Errorf("expected the output to contain\n%v\ngot:\n%v", expected, generated)
+		}
+	}
+}
+
+func TestEndpointModules(t *testing.T) {
+	reg := NewRegistry().
+		Add(SimpleStructWithTimeFields{}, Type{Codec: true, Module: "common"}).
+		Add(Endpoint{Path: "/users/{id}", Response: SimpleStructWithTimeFields{}}).
+		Add(Endpoint{Method: "POST", Path: "/users", Request: CreateUserRequest{}})
+	reg.Index = true
+	reg.Client = "api"
+
+	out := NewMemFS()
+	if err := reg.GenerateFS(out, Settings{Logger: DiscardLogger}); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := out.ReadFile("api.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsAll(string(client),
+		`import type { SimpleStructWithTimeFields } from "./common"`,
+		`import type { CreateUserRequest } from "./types"`,
+		`import { decodeSimpleStructWithTimeFields } from "./common"`,
+	) {
+		t.Errorf("unexpected imports of the client:\n%s", client)
+	}
+
+	index, err := out.ReadFile("index.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsAll(string(index),
+		`export { decodeSimpleStructWithTimeFields, encodeSimpleStructWithTimeFields } from "./common"`,
+		`export type { ClientOptions } from "./api"`,
+		`export { ApiError, createClient } from "./api"`,
+	) {
+		t.Errorf("unexpected index:\n%s", index)
+	}
+}
+
+func TestInvalidEndpoints(t *testing.T) {
+	tests := []Endpoint{
+		{Method: "FETCH", Path: "/users"},
+		{Path: "users"},
+		{Name: "Invalid-Name", Path: "/users"},
+	}
+
+	for _, ep := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for %+v", ep)
+				}
+			}()
+			NewRegistry().Add(ep)
+		}()
+	}
+}
@@ -0,0 +1,197 @@
+package gut
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	r "reflect"
+	"sort"
+	"strings"
+
+	"github.com/tompston/gut/ir"
+)
+
+// EnumType describes the values of a Go enum, which is created
+// with the Enum or the LabeledEnum function.
+type EnumType struct {
+	typ r.Type
+	// json values of the enum
+	values []interface{}
+	// optional labels of the values
+	labels []string
+}
+
+// Enum creates an enum of the values, which is converted into a union of
+// the json values (`export type Status = "active" | "disabled"`), followed
+// by the array of the values (`export const StatusValues = [...] as const`).
+// If the type has a String() method, the labels of the values are emitted
+// as well (`export const StatusLabels: Record<Status, string>`).
+//
+// When the enum is added to a Registry, the fields of the type T
+// reference the enum, instead of being converted to the underlying type.
+//
+// Example
+//
+//	type Status string
+//
+//	const (
+//		StatusActive   Status = "active"
+//		StatusDisabled Status = "disabled"
+//	)
+//
+//	reg.Add(gut.Enum(StatusActive, StatusDisabled))
+func Enum[T any](values ...T) EnumType {
+	en := newEnum(r.TypeOf((*T)(nil)).Elem(), len(values))
+
+	var labels []string
+	for _, v := range values {
+		en.addValue(v)
+		if s, ok := interface{}(v).(fmt.Stringer); ok {
+			labels = append(labels, s.String())
+		}
+	}
+	if len(labels) == len(values) {
+		en.labels = labels
+	}
+	return en
+}
+
+// LabeledEnum works like Enum, but the labels of the values are taken
+// from the map. The values are emitted in the passed in order, or sorted
+// (numbers by their value and others by their json), if they are not
+// passed in.
+//
+// Example
+//
+//	reg.Add(gut.LabeledEnum(map[Status]string{
+//		StatusActive:   "Active",
+//		StatusDisabled: "Disabled",
+//	}))
+func LabeledEnum[T comparable](labels map[T]string, values ...T) EnumType {
+	if len(values) == 0 {
+		for v := range labels {
+			values = append(values, v)
+		}
+		sort.Slice(values, func(i, j int) bool {
+			return enumLess(r.ValueOf(values[i]), r.ValueOf(values[j]))
+		})
+	}
+
+	en := newEnum(r.TypeOf((*T)(nil)).Elem(), len(values))
+	for _, v := range values {
+		label, ok := labels[v]
+		if !ok {
+			panic(fmt.Sprintf("The value %v of the enum %v does not have a label!", v, en.typ))
+		}
+		en.addValue(v)
+		en.labels = append(en.labels, label)
+	}
+	return en
+}
+
+// newEnum checks the type of the enum
+func newEnum(typ r.Type, values int) EnumType {
+	if typ.PkgPath() == "" || typ.Kind() == r.Struct || typ.Kind() == r.Interface {
+		panic(fmt.Sprintf("Only named types which are not structs can be enums! %v", typ))
+	}
+	if values == 0 {
+		panic(fmt.Sprintf("The enum %v does not have any values!", typ))
+	}
+	return EnumType{typ: typ}
+}
+
+// addValue adds the json value of the Go value to the enum.
+// Only the values which are marshalled as strings or numbers
+// (which can be the keys of the labels) are supported. The numbers
+// are stored as float64, like the other literals of the IR.
+func (en *EnumType) addValue(v interface{}) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(enumValue(v)), &value); err != nil {
+		panic(fmt.Sprintf("The value %v of the enum %v can not be marshalled! %v", v, en.typ, err))
+	}
+	switch value.(type) {
+	case string, float64:
+	default:
+		panic(fmt.Sprintf("The value %v of the enum %v is not marshalled as a string or a number!", v, en.typ))
+	}
+
+	for _, existing := range en.values {
+		if existing == value {
+			panic(fmt.Sprintf("The value %v of the enum %v is added more than once!", v, en.typ))
+		}
+	}
+	en.values = append(en.values, value)
+}
+
+// enumLess compares the values of the enum, so that the numbers are
+// not sorted lexicographically (10 before 2)
+func enumLess(a, b r.Value) bool {
+	switch a.Kind() {
+	case r.Int, r.Int8, r.Int16, r.Int32, r.Int64:
+		return a.Int() < b.Int()
+	case r.Uint, r.Uint8, r.Uint16, r.Uint32, r.Uint64, r.Uintptr:
+		return a.Uint() < b.Uint()
+	case r.Float32, r.Float64:
+		return a.Float() < b.Float()
+	default:
+		return enumValue(a.Interface()) < enumValue(b.Interface())
+	}
+}
+
+// enumValue returns the json value of the Go value (or an empty string)
+func enumValue(v interface{}) string {
+	value, _ := json.Marshal(v)
+	return string(bytes.TrimSpace(value))
+}
+
+// convertEnum converts the enum
+func convertEnum(en EnumType, gutType Type) string {
+	if gutType.Name == "" {
+		gutType.Name = ir.TypeName(en.typ)
+	}
+	if !isValidTypeName(gutType.Name) {
+		panic(fmt.Sprintf("Invalid typescript enum name was provided! %v", gutType.Name))
+	}
+
+	settings := map[*ir.Decl]Type{}
+	b := newBuilder(settings)
+	d := b.DeclareEnum(en.typ, gutType.Name, en.values, en.labels)
+	settings[d] = gutType
+	return emitDecls(b, []*ir.Decl{d}, settings)
+}
+
+// isEnum checks if the declaration is an enum (a union of literals)
+func isEnum(decl *ir.Decl) bool {
+	if decl.Kind != ir.AliasDecl || decl.Type.Kind != ir.Union || len(decl.Type.Variants) == 0 {
+		return false
+	}
+	for _, v := range decl.Type.Variants {
+		if v.Kind != ir.Literal {
+			return false
+		}
+	}
+	return true
+}
+
+// enumConstants returns the array of the values of the enum
+// and the labels of the values (if they are present).
+func (e *tsEmitter) enumConstants(decl *ir.Decl) string {
+	values := make([]string, 0, len(decl.Type.Variants))
+	for _, v := range decl.Type.Variants {
+		literal, _ := json.Marshal(v.Literal)
+		values = append(values, string(literal))
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("export const %sValues = [%s] as const\n\n", decl.Name, strings.Join(values, ", ")))
+
+	if len(decl.Labels) == len(values) {
+		sb.WriteString(fmt.Sprintf("export const %sLabels: Record<%s, string> = {\n", decl.Name, decl.Name))
+		for i, label := range decl.Labels {
+			quoted, _ := json.Marshal(label)
+			sb.WriteString(fmt.Sprintf("  %s: %s,\n", values[i], quoted))
+		}
+		sb.WriteString("}\n\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,146 @@
+package gut
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/tompston/gut/types"
+)
+
+func TestEnum(t *testing.T) {
+	type Level int
+
+	tests := []struct {
+		generated string
+		expected  string
+	}{
+		{
+			generated: Convert(Enum(StatusActive, StatusDisabled)),
+			expected: `
+			export type Status = "active" | "disabled"
+
+			export const StatusValues = ["active", "disabled"] as const`,
+		},
+		{
+			// the labels are taken from the String() method
+			generated: Convert(Enum(PriorityLow, PriorityHigh), Type{Name: "TicketPriority"}),
+			expected: `
+			export type TicketPriority = 0 | 1
+
+			export const TicketPriorityValues = [0, 1] as const
+
+			export const TicketPriorityLabels: Record<TicketPriority, string> = {
+				0: "Low",
+				1: "High",
+			}`,
+		},
+		{
+			// the values are ordered by their json values
+			generated: Convert(LabeledEnum(map[Status]string{StatusDisabled: "Disabled", StatusActive: "Active"}), Type{Guard: true}),
+			expected: `
+			export type Status = "active" | "disabled"
+
+			export const StatusValues = ["active", "disabled"] as const
+
+			export const StatusLabels: Record<Status, string> = {
+				"active": "Active",
+				"disabled": "Disabled",
+			}
+
+			export function isStatus(x: unknown): x is Status {
+				return (x === "active" || x === "disabled")
+			}`,
+		},
+		{
+			// the numbers are ordered by their values
+			generated: Convert(LabeledEnum(map[Level]string{10: "High", 2: "Low", 5: "Medium"})),
+			expected: `
+			export type Level = 2 | 5 | 10
+
+			export const LevelValues = [2, 5, 10] as const
+
+			export const LevelLabels: Record<Level, string> = {
+				2: "Low",
+				5: "Medium",
+				10: "High",
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		if stripSpaces(tt.generated) != stripSpaces(tt.expected) {
+			t.Errorf("expected: %v\n, got: %v\n", tt.expected, tt.generated)
+		}
+	}
+}
+
+func TestEnumLiterals(t *testing.T) {
+	// the numbers are stored as float64, like the other literals of the IR
+	en := Enum(PriorityLow, PriorityHigh)
+	for i, expected := range []float64{0, 1} {
+		if value, ok := en.values[i].(float64); !ok || value != expected {
+			t.Errorf("expected the literal %v to be a float64, got %T(%v)", expected, en.values[i], en.values[i])
+		}
+	}
+}
+
+func TestRegistryEnums(t *testing.T) {
+	generated := NewRegistry().
+		Add(Ticket{}).
+		Add(Enum(StatusDisabled, StatusActive)).
+		Add(Enum(PriorityLow, PriorityHigh)).
+		Convert()
+
+	expected := `
+	export interface Ticket {
+		status: Status
+		priority: Priority
+	}
+
+	export type Status = "disabled" | "active"
+
+	export const StatusValues = ["disabled", "active"] as const
+
+	export type Priority = 0 | 1`
+
+	if !strings.HasPrefix(stripSpaces(generated), stripSpaces(expected)) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+
+	schema, err := NewRegistry().Add(Ticket{}).Add(Enum(StatusActive, StatusDisabled)).Emit("jsonschema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(schema), &parsed); err != nil || !strings.Contains(schema, `"const": "disabled"`) {
+		t.Errorf("expected the values of the enum in the schema (%v), got:\n%s", err, schema)
+	}
+}
+
+type invalidEnum struct{ Value string }
+
+func TestInvalidEnums(t *testing.T) {
+	tests := []struct {
+		fn  func()
+		err string
+	}{
+		{func() { Enum[Status]() }, "does not have any values"},
+		{func() { Enum("a", "b") }, "Only named types"},
+		{func() { Enum(invalidEnum{}) }, "Only named types"},
+		{func() { Enum(StatusActive, StatusActive) }, "is added more than once"},
+		{func() { LabeledEnum(map[Status]string{StatusActive: "Active"}, StatusActive, StatusDisabled) }, "does not have a label"},
+	}
+
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if err := recover(); err == nil || !strings.Contains(fmt.Sprint(err), tt.err) {
+					t.Errorf("expected %q, got %v", tt.err, err)
+				}
+			}()
+			tt.fn()
+		}()
+	}
+}
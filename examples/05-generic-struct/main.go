@@ -30,10 +30,6 @@ type GenericInsideGeneric StructWithGeneric[GenericWithAnObject]
 type GenericInsideGenericInsideGeneric StructWithGeneric[GenericInsideGeneric]
 
 /*
-export type UuidType = string;
-export type BigIntType = BigInt;
-export type DateType = Date;
-
 export interface GenericWithAnObject {
   some_field: string;
   areas: { [key: string]: any };
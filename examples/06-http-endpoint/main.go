@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tompston/gut"
+)
+
+// go run examples/06-http-endpoint/main.go
+//
+// curl http://localhost:8080/types.ts
+func main() {
+
+	interfaces := gut.Convert(User{})
+
+	// serve the generated types, instead of writing them to a file
+	http.HandleFunc("/types.ts", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/typescript")
+		if err := gut.Render(w, interfaces, gut.Settings{DateType: "string"}); err != nil {
+			fmt.Println(err)
+		}
+	})
+
+	fmt.Println("serving the generated types on http://localhost:8080/types.ts")
+	if err := http.ListenAndServe(":8080", nil); err != nil {
+		fmt.Println(err)
+	}
+}
+
+type User struct {
+	ID        uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
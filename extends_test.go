@@ -0,0 +1,115 @@
+package gut
+
+import (
+	"testing"
+
+	. "github.com/tompston/gut/types"
+)
+
+func TestExtendsConversion(t *testing.T) {
+	tests := []struct {
+		input    interface{}
+		settings Type
+		expected string
+	}{
+		{
+			input:    StructWhichHasEmbeddedStructs{},
+			settings: Type{Extends: true},
+			expected: `
+				export interface StructWhichHasEmbeddedStructs extends MyEmbeddedStruct {
+				}
+
+				export interface MyEmbeddedStruct {
+					start_time: DateType
+					end_time: DateType
+					updated_at: DateType
+				}`,
+		},
+		{
+			input:    StructWithOverriddenField{},
+			settings: Type{Extends: true},
+			expected: `
+				export type StructWithOverriddenField = Omit<MyEmbeddedStruct, "updated_at"> & {
+					updated_at: string
+				}
+
+				export interface MyEmbeddedStruct {
+					start_time: DateType
+					end_time: DateType
+					updated_at: DateType
+				}`,
+		},
+		{
+			// embedded structs without a json name are inlined as well
+			input:    StructWithUnspecifiedStructName{},
+			settings: Type{Extends: true},
+			expected: `
+				export interface StructWithUnspecifiedStructName extends ReferenceStruct {
+					SomeValue: string
+				}
+
+				export interface ReferenceStruct {
+					my_float: number
+					timestamp: number
+				}`,
+		},
+		{
+			// the fields are copied by default
+			input: StructWhichHasEmbeddedStructs{},
+			expected: `
+				export interface StructWhichHasEmbeddedStructs {
+					start_time: DateType
+					end_time: DateType
+					updated_at: DateType
+				}`,
+		},
+	}
+
+	for _, tt := range tests {
+		if generated := Convert(tt.input, tt.settings); stripSpaces(generated) != stripSpaces(tt.expected) {
+			t.Errorf("expected: %v\n, got: %v\n", tt.expected, generated)
+		}
+	}
+}
+
+func TestExtendsInRegistry(t *testing.T) {
+	reg := NewRegistry().
+		Add(StructWithInlinedFields{}, Type{Extends: true}).
+		Add(MyEmbeddedStruct{}, Type{Module: "common"})
+
+	out := NewMemFS()
+	if err := reg.GenerateFS(out, Settings{Logger: DiscardLogger, DateType: "string"}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := out.ReadFile("types.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+		export type DateType = string
+
+		import type { MyEmbeddedStruct } from "./common"
+
+		export interface StructWithInlinedFields extends MyEmbeddedStruct {
+			not_embedded_struct: {
+				some_random_field: string
+				SomeMoreStuff: {[key: string]: any}
+			}
+			CustomField: number
+			this_should_hold_start_end_and_updated_at: {
+				start_time: DateType
+				end_time: DateType
+				updated_at: DateType
+			}
+		}`
+
+	if stripSpaces(string(content)) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, string(content))
+	}
+
+	if _, err := out.ReadFile("common.ts"); err != nil {
+		t.Errorf("the base was not declared in its module: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package gut
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// Logger is used to report the status of the generated files.
+// *log.Logger satisfies this interface.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// DiscardLogger can be used as the Settings.Logger in order
+// to silence the output of the Generate function.
+var DiscardLogger Logger = discardLogger{}
+
+type discardLogger struct{}
+
+func (discardLogger) Printf(format string, v ...interface{}) {}
+
+// Default settings for the generated typescript file. Be free to create a custom Settings struct if needed.
+var defaultSettings = Settings{
+	DateType:  "Date",
+	UuidType:  "string",
+	Int64Mode: Int64Number,
+}
+
+// getSettings returns the first passed in settings, or the
+// default settings if none were provided.
+func getSettings(settings []Settings) Settings {
+	if len(settings) == 1 {
+		return settings[0]
+	}
+	return defaultSettings
+}
+
+// Generate function creates a file  and saves the passed in content to it + appends
+// the settings types at the start. If the 3rd optional param is present, it will be used
+// to override the default settings.
+//
+// The file is written atomically (the content is written to a temporary
+// file which then replaces the target), and is not touched at all if
+// its content is already up to date.
+//
+// The content is the typescript returned by Convert, so the output of the
+// other emitters is written with Registry.Outputs or Registry.Emit instead.
+func Generate(filename string, content string, settings ...Settings) error {
+	return GenerateFS(osOutput, filename, content, settings...)
+}
+
+// GenerateFS works like Generate, but writes the file to the passed in
+// Output, instead of the OS filesystem.
+//
+// Example
+//
+//	out := gut.NewMemFS()
+//	err := gut.GenerateFS(out, "types/example.gen.ts", interfaces)
+//	data, err := fs.ReadFile(out, "types/example.gen.ts")
+func GenerateFS(out Output, name string, content string, settings ...Settings) error {
+	s := getSettings(settings)
+	return writeGenerated(out, name, []byte(render(content, s)), s)
+}
+
+// writeGenerated writes the data to the output, unless the
+// file already holds the same data, and logs the status.
+func writeGenerated(out Output, name string, data []byte, s Settings) error {
+	current, err := out.ReadFile(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	exists := err == nil
+
+	if exists && bytes.Equal(current, data) {
+		logStatus(s, "UNCHANGED", name)
+		return nil
+	}
+
+	if err := out.WriteFile(name, data); err != nil {
+		return err
+	}
+
+	if !exists {
+		logStatus(s, "CREATED", name)
+	} else {
+		logStatus(s, "UPDATED", name)
+	}
+	return nil
+}
+
+// Check compares the content which Generate would write to the file
+// with the current content of the file. If the file is stale (or does
+// not exist), a unified diff between the two is returned. An empty
+// string means that the file is up to date.
+//
+// Example
+//
+//	diff, err := gut.Check("./example.gen.ts", interfaces)
+//	if diff != "" {
+//		log.Fatalf("example.gen.ts is out of date:\n%s", diff)
+//	}
+func Check(filename string, content string, settings ...Settings) (string, error) {
+	return CheckFS(osOutput, filename, content, settings...)
+}
+
+// CheckFS works like Check, but reads the file from the passed in Output.
+func CheckFS(out Output, name string, content string, settings ...Settings) (string, error) {
+	s := getSettings(settings)
+
+	current, err := out.ReadFile(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	return unifiedDiff(name, string(current), render(content, s)), nil
+}
+
+// Render writes the generated file (the header created from the
+// settings + the passed in content) to w, which makes it possible to
+// serve the generated types over http or embed them somewhere else.
+//
+// Example
+//
+//	http.HandleFunc("/types.ts", func(w http.ResponseWriter, r *http.Request) {
+//		w.Header().Set("Content-Type", "application/typescript")
+//		gut.Render(w, interfaces, gut.Settings{DateType: "string"})
+//	})
+func Render(w io.Writer, content string, settings Settings) error {
+	_, err := io.WriteString(w, render(content, settings))
+	return err
+}
+
+// render returns the content of the generated file, which
+// consists of the header and the passed in interfaces.
+func render(content string, s Settings) string {
+	return fmt.Sprintln(createHeader(s, content), content)
+}
+
+func logStatus(s Settings, status string, filename string) {
+	if s.Logger != nil {
+		s.Logger.Printf("%s %s", status, filename)
+		return
+	}
+
+	color := "32" // green
+	switch status {
+	case "UPDATED":
+		color = "33" // yellow
+	case "UNCHANGED":
+		color = "90" // gray
+	}
+	fmt.Printf("\033[%sm * %s\033[0m  %s\n", color, status, filename)
+}
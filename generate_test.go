@@ -0,0 +1,225 @@
+package gut
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	. "github.com/tompston/gut/types"
+)
+
+// recordingLogger stores the messages which were logged
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func TestGenerateSkipsUnchangedFiles(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "example.gen.ts")
+	logger := &recordingLogger{}
+	settings := Settings{Logger: logger}
+
+	for _, content := range []string{Convert(SimpleStruct{}), Convert(SimpleStruct{}), Convert(SimpleStructWithJsonTags{})} {
+		if err := Generate(filename, content, settings); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{
+		"CREATED " + filename,
+		"UNCHANGED " + filename,
+		"UPDATED " + filename,
+	}
+	if strings.Join(logger.messages, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, logger.messages)
+	}
+
+	// no temporary files should be left behind
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the generated file in the directory, got %v", entries)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "example.gen.ts")
+	settings := Settings{Logger: DiscardLogger}
+
+	// the file does not exist yet, so the whole content is added
+	diff, err := Check(filename, Convert(SimpleStructWithTimeFields{}), settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "+export type DateType = Date\n") || !strings.Contains(diff, "export interface SimpleStructWithTimeFields {") {
+		t.Fatalf("expected the diff to hold the missing interface, got: %v\n", diff)
+	}
+
+	if err := Generate(filename, Convert(SimpleStruct{}), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err = Check(filename, Convert(SimpleStruct{}), settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Fatalf("expected an up to date file, got: %v\n", diff)
+	}
+
+	diff, err = Check(filename, Convert(SimpleStructWithJsonTags{}), settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"- export interface SimpleStruct {\n",
+		"-  MyString: string\n",
+		"+ export interface SimpleStructWithJsonTags {\n",
+		"+  my_str: string\n",
+		"+  my_int: number\n",
+	} {
+		if !strings.Contains(diff, line) {
+			t.Fatalf("expected the diff to contain %q, got:\n%v", line, diff)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	current := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	wanted := "a\nb\nC\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\n"
+
+	expected := `--- file.ts
++++ file.ts (generated)
+@@ -1,6 +1,6 @@
+ a
+ b
+-c
++C
+ d
+ e
+ f
+@@ -11,3 +11,4 @@
+ k
+ l
+ m
++n
+`
+	if diff := unifiedDiff("file.ts", current, wanted); diff != expected {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, diff)
+	}
+}
+
+func TestUnifiedDiffNewline(t *testing.T) {
+	expected := `--- file.ts
++++ file.ts (generated)
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`
+	if diff := unifiedDiff("file.ts", "a\nb", "a\nb\n"); diff != expected {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, diff)
+	}
+}
+
+func TestUnifiedDiffLimit(t *testing.T) {
+	var current, wanted strings.Builder
+	for i := 0; i < 3*diffMaxEdits; i++ {
+		current.WriteString(fmt.Sprintf("a%d\n", i))
+		wanted.WriteString(fmt.Sprintf("b%d\n", i))
+	}
+
+	// the lines are replaced, when the files differ too much
+	diff := unifiedDiff("file.ts", "first\n"+current.String(), "first\n"+wanted.String())
+	if !strings.HasPrefix(diff, fmt.Sprintf("--- file.ts\n+++ file.ts (generated)\n@@ -1,%d +1,%d @@\n first\n-a0\n", 3*diffMaxEdits+1, 3*diffMaxEdits+1)) ||
+		strings.Count(diff, "\n-") != 3*diffMaxEdits || strings.Count(diff, "\n+") != 3*diffMaxEdits+1 {
+		t.Fatalf("unexpected diff:\n%v", diff[:200])
+	}
+}
+
+func TestRender(t *testing.T) {
+	var buf strings.Builder
+	content := Convert(SimpleStructWithTimeFields{})
+
+	if err := Render(&buf, content, Settings{DateType: "string"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+	export type DateType = string
+
+	export interface SimpleStructWithTimeFields {
+		MyString: string
+		CreatedAt: DateType
+		updated_at: DateType
+		deleted_at: DateType
+	}`
+	if stripSpaces(buf.String()) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, buf.String())
+	}
+}
+
+func TestGenerateFS(t *testing.T) {
+	out := NewMemFS()
+	settings := Settings{Logger: DiscardLogger}
+	content := Convert(SimpleStruct{})
+
+	if err := GenerateFS(out, "types/example.gen.ts", content, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf strings.Builder
+	if err := Render(&buf, content, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := fs.ReadFile(out, "types/example.gen.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != buf.String() {
+		t.Fatalf("expected: %v\n, got: %v\n", buf.String(), string(data))
+	}
+
+	if diff, err := CheckFS(out, "types/example.gen.ts", content, settings); err != nil || diff != "" {
+		t.Fatalf("expected an up to date file, got: %v %v\n", diff, err)
+	}
+
+	// the directories are also available through the fs.FS interface
+	matches, err := fs.Glob(out, "types/*.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0] != "types/example.gen.ts" {
+		t.Fatalf("expected the generated file to be found, got: %v\n", matches)
+	}
+	if err := out.WriteFile("types/nested/other.ts", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(out, "types/example.gen.ts", "types/nested/other.ts"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same content is written to the disk, when using Dir
+	dir := t.TempDir()
+	if err := GenerateFS(Dir(dir), "types/example.gen.ts", content, settings); err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "types", "example.gen.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != buf.String() {
+		t.Fatalf("expected: %v\n, got: %v\n", buf.String(), string(data))
+	}
+}
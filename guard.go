@@ -0,0 +1,202 @@
+package gut
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tompston/gut/ir"
+)
+
+// guardFunction returns the is<Name> type guard of the declaration, which
+// structurally checks the required fields, the primitive types, the
+// arrays and the nested objects. The values of the header aliases are
+// checked with the is<Alias> helpers, which are based on the Settings.
+func (e *tsEmitter) guardFunction(decl *ir.Decl) string {
+	var check string
+	if decl.Kind == ir.AliasDecl {
+		check = e.guardCheck(decl.Type, "x", 0)
+	} else {
+		check = e.objectGuard(decl.Fields, "x", 0)
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("export function is%s(x: unknown): x is %s {\n", decl.Name, decl.Name))
+	sb.WriteString(fmt.Sprintf("  return %s\n", check))
+	sb.WriteString("}\n\n")
+	return sb.String()
+}
+
+// objectGuard checks that the value is an object with the fields
+func (e *tsEmitter) objectGuard(fields []*ir.Field, value string, depth int) string {
+	checks := []string{fmt.Sprintf("%s(%s)", e.helper("isObject"), value)}
+	for _, field := range flattenFields(fields) {
+		prop := fmt.Sprintf("%s[%s]", value, quote(field.JSONName))
+
+		check := e.fieldGuard(field, prop, depth)
+		if check == "true" {
+			if field.Optional {
+				continue
+			}
+			check = fmt.Sprintf("%s in %s", quote(field.JSONName), value)
+		}
+
+		if field.Optional {
+			check = fmt.Sprintf("(%s === undefined || %s)", prop, check)
+		}
+		checks = append(checks, check)
+	}
+	return strings.Join(checks, " &&\n    ")
+}
+
+// fieldGuard checks the value of the field, taking into
+// account the ",string" json option.
+func (e *tsEmitter) fieldGuard(field *ir.Field, value string, depth int) string {
+	if typ := field.Type.Unwrap(); field.Stringified && typ.Kind == ir.Primitive {
+		switch typ.Primitive {
+		case ir.Int64:
+			return e.optionGuard(field.Type, value, fmt.Sprintf("%s(%s)", e.helper("isBigIntStringType"), value))
+		case ir.String, ir.Boolean, ir.Number, ir.Duration:
+			return e.optionGuard(field.Type, value, fmt.Sprintf("typeof %s === \"string\"", value))
+		}
+	}
+	return e.guardCheck(field.Type, value, depth)
+}
+
+// optionGuard allows null values, if the type is an option
+func (e *tsEmitter) optionGuard(typ *ir.Type, value string, check string) string {
+	if typ.Kind == ir.Option {
+		return fmt.Sprintf("(%s === null || %s)", value, check)
+	}
+	return check
+}
+
+// guardCheck returns the typescript expression which checks that the
+// value has the type. "true" is returned if the value is not checked.
+func (e *tsEmitter) guardCheck(typ *ir.Type, value string, depth int) string {
+	switch typ.Kind {
+
+	case ir.Primitive:
+		switch typ.Primitive {
+		case ir.String, ir.Boolean, ir.Number:
+			return fmt.Sprintf("typeof %s === \"%s\"", value, e.toTS(typ))
+		case ir.Int64:
+			return fmt.Sprintf("%s(%s)", e.helper("isBigIntType"), value)
+		case ir.Time:
+			return fmt.Sprintf("%s(%s)", e.helper("isDateType"), value)
+		case ir.Duration:
+			return fmt.Sprintf("%s(%s)", e.helper("isDurationType"), value)
+		case ir.UUID:
+			return fmt.Sprintf("%s(%s)", e.helper("isUuidType"), value)
+		default:
+			return "true"
+		}
+
+	case ir.Reference:
+		e.referenced[typ.Decl] = true
+		if e.settings[typ.Decl].Guard {
+			return fmt.Sprintf("%s(%s)", e.qualified(typ.Decl, "is"+typ.Decl.Name), value)
+		}
+		if typ.Decl.Kind == ir.AliasDecl {
+			return e.guardCheck(typ.Decl.Type, value, depth)
+		}
+		// the fields of declarations without a guard are not checked
+		return fmt.Sprintf("%s(%s)", e.helper("isObject"), value)
+
+	case ir.Object:
+		return fmt.Sprintf("(%s)", e.objectGuard(typ.Fields, value, depth))
+
+	case ir.Array:
+		// nil slices are marshalled as null
+		elem := fmt.Sprintf("e%d", depth)
+		check := fmt.Sprintf("Array.isArray(%s)", value)
+		if elemCheck := e.guardCheck(typ.Elem, elem, depth+1); elemCheck != "true" {
+			check += fmt.Sprintf(" && %s.every((%s: any) => %s)", value, elem, elemCheck)
+		}
+		return fmt.Sprintf("(%s === null || %s)", value, check)
+
+	case ir.Map:
+		elem := fmt.Sprintf("e%d", depth)
+		check := fmt.Sprintf("%s(%s)", e.helper("isObject"), value)
+		if elemCheck := e.guardCheck(typ.Elem, elem, depth+1); elemCheck != "true" {
+			check += fmt.Sprintf(" && Object.values(%s).every((%s: any) => %s)", value, elem, elemCheck)
+		}
+		return fmt.Sprintf("(%s === null || %s)", value, check)
+
+	case ir.Option:
+		check := e.guardCheck(typ.Elem, value, depth)
+		if check == "true" || typ.Elem.Kind == ir.Array || typ.Elem.Kind == ir.Map {
+			return check
+		}
+		return fmt.Sprintf("(%s === null || %s)", value, check)
+
+	case ir.Union:
+		checks := make([]string, 0, len(typ.Variants))
+		for _, v := range typ.Variants {
+			check := e.guardCheck(v, value, depth)
+			if check == "true" {
+				return check
+			}
+			checks = append(checks, check)
+		}
+		return fmt.Sprintf("(%s)", strings.Join(checks, " || "))
+
+	case ir.Literal:
+		literal, _ := json.Marshal(typ.Literal)
+		return fmt.Sprintf("%s === %s", value, literal)
+
+	case ir.Func:
+		return fmt.Sprintf("typeof %s === \"function\"", value)
+
+	default:
+		// custom aliases are not checked
+		return "true"
+	}
+}
+
+// flattenFields returns the fields with the fields of the inlined structs
+func flattenFields(fields []*ir.Field) []*ir.Field {
+	var flat []*ir.Field
+	for _, f := range fields {
+		if f.Inline {
+			flat = append(flat, flattenFields(f.Type.Fields)...)
+		} else {
+			flat = append(flat, f)
+		}
+	}
+	return flat
+}
+
+func isObjectHelper(Settings) string {
+	return `export function isObject(v: unknown): v is Record<string, any> {
+  return typeof v === "object" && v !== null && !Array.isArray(v)
+}
+`
+}
+
+// aliasGuardHelper returns the is<Alias> helper, which checks
+// the value based on the typescript type of the alias.
+func aliasGuardHelper(name string) func(Settings) string {
+	return func(s Settings) string {
+		return fmt.Sprintf("export function is%s(v: unknown): v is %s {\n  return %s\n}\n", name, qualifiedAlias(name, s), typeofCheck(aliasType(name, s), "v"))
+	}
+}
+
+// typeofCheck returns the runtime check of the simple typescript
+// types (like "string" or "Date"). Other types are not checked.
+func typeofCheck(tsType string, value string) string {
+	var checks []string
+	for _, t := range strings.Split(tsType, "|") {
+		switch t = strings.TrimSpace(t); t {
+		case "string", "number", "boolean", "bigint":
+			checks = append(checks, fmt.Sprintf("typeof %s === \"%s\"", value, t))
+		case "Date":
+			checks = append(checks, fmt.Sprintf("%s instanceof Date", value))
+		case "null":
+			checks = append(checks, fmt.Sprintf("%s === null", value))
+		default:
+			return "true"
+		}
+	}
+	return strings.Join(checks, " || ")
+}
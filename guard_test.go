@@ -0,0 +1,105 @@
+package gut
+
+import (
+	"testing"
+
+	. "github.com/tompston/gut/types"
+)
+
+func TestGuardConversion(t *testing.T) {
+	generated := Convert(StructWithMultipleTypes{}, Type{Guard: true})
+
+	expected := `
+	export interface StructWithMultipleTypes {
+		my_str: string
+		my_int: number
+		my_int_32: number
+		ArrayOfStrings: string[]
+		opt_arr_of_ints?: number[]
+		MyInterface: any
+	}
+
+	export function isStructWithMultipleTypes(x: unknown): x is StructWithMultipleTypes {
+		return isObject(x) &&
+			typeof x["my_str"] === "string" &&
+			typeof x["my_int"] === "number" &&
+			typeof x["my_int_32"] === "number" &&
+			(x["ArrayOfStrings"] === null || Array.isArray(x["ArrayOfStrings"]) && x["ArrayOfStrings"].every((e0: any) => typeof e0 === "string")) &&
+			(x["opt_arr_of_ints"] === undefined || (x["opt_arr_of_ints"] === null || Array.isArray(x["opt_arr_of_ints"]) && x["opt_arr_of_ints"].every((e0: any) => typeof e0 === "number"))) &&
+			"MyInterface" in x
+	}`
+
+	if stripSpaces(generated) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+}
+
+func TestGuardReferences(t *testing.T) {
+	reg := NewRegistry().
+		Add(StructWithReference{}, Type{Guard: true}).
+		Add(StructWithArrayOfReferences{}, Type{Guard: true}).
+		Add(ReferenceStruct{})
+
+	expected := `
+	export interface StructWithReference {
+		my_str: string
+		MyInt: number
+		ref: ReferenceStruct
+		opt_ref: ReferenceStruct
+	}
+
+	export function isStructWithReference(x: unknown): x is StructWithReference {
+		return isObject(x) &&
+			typeof x["my_str"] === "string" &&
+			typeof x["MyInt"] === "number" &&
+			isObject(x["ref"]) &&
+			isObject(x["opt_ref"])
+	}
+
+	export interface StructWithArrayOfReferences {
+		arr_of_ref: ReferenceStruct[]
+	}
+
+	export function isStructWithArrayOfReferences(x: unknown): x is StructWithArrayOfReferences {
+		return isObject(x) &&
+			(x["arr_of_ref"] === null || Array.isArray(x["arr_of_ref"]) && x["arr_of_ref"].every((e0: any) => isObject(e0)))
+	}
+
+	export interface ReferenceStruct {
+		my_float: number
+		timestamp: number
+	}`
+
+	if generated := reg.Convert(); stripSpaces(generated) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+}
+
+func TestGuardHelpers(t *testing.T) {
+	content := Convert(SimpleStructWithTimeFields{}, Type{Guard: true})
+
+	tests := []struct {
+		settings Settings
+		expected []string
+	}{
+		{
+			settings: Settings{DateType: "Date"},
+			expected: []string{"export function isDateType(v: unknown): v is DateType {\n  return v instanceof Date\n}", "export function isObject("},
+		},
+		{
+			settings: Settings{DateType: "string | null"},
+			expected: []string{"return typeof v === \"string\" || v === null"},
+		},
+		{
+			settings: Settings{DateType: "Moment"},
+			expected: []string{"export function isDateType(v: unknown): v is DateType {\n  return true\n}"},
+		},
+	}
+
+	for _, tt := range tests {
+		header := createHeader(tt.settings, content)
+		if !containsAll(header, tt.expected...) {
+			t.Errorf("expected the header to contain %q, got:\n%v", tt.expected, header)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package gut
+
+import (
+	"fmt"
+	r "reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// alias is a type alias which is declared in the header
+// of the generated file, if it is used by the interfaces.
+type alias struct {
+	name string
+	// returns the typescript type of the alias
+	tsType func(s Settings) string
+}
+
+var builtinAliases = []alias{
+	{"UuidType", func(s Settings) string { return valueOr(s.UuidType, "string") }},
+	{"BigIntType", func(s Settings) string { return valueOr(s.BigIntType, int64Mode(s).tsType()) }},
+	{"BigIntStringType", func(s Settings) string { return int64Mode(s).stringTSType() }},
+	{"DateType", func(s Settings) string { return valueOr(s.DateType, "Date") }},
+	{"DurationType", func(s Settings) string { return valueOr(s.DurationType, durationFormat(s).tsType()) }},
+	{"ErrorType", func(s Settings) string { return valueOr(s.ErrorType, "Error") }},
+}
+
+// helper is a runtime function which is declared in the header of
+// the generated file, if it is used by the emitter or if the
+// Settings.Helpers flag is set and the alias of the helper is used.
+type helper struct {
+	name string
+	// alias to which the helper belongs
+	alias string
+	// returns the typescript code of the helper
+	code func(s Settings) string
+	// aliases which are used by the code of the helper
+	uses []string
+}
+
+var helpers = []helper{
+	{"parseDuration", "DurationType", parseDurationHelper, []string{"DurationType"}},
+	{"formatDuration", "DurationType", formatDurationHelper, []string{"DurationType"}},
+	{"reviveBigInts", "", reviveBigIntsHelper, nil},
+	{"isObject", "", isObjectHelper, nil},
+	{"isDateType", "", aliasGuardHelper("DateType"), []string{"DateType"}},
+	{"isUuidType", "", aliasGuardHelper("UuidType"), []string{"UuidType"}},
+	{"isBigIntType", "", aliasGuardHelper("BigIntType"), []string{"BigIntType"}},
+	{"isBigIntStringType", "", aliasGuardHelper("BigIntStringType"), []string{"BigIntStringType"}},
+	{"isDurationType", "", aliasGuardHelper("DurationType"), []string{"DurationType"}},
+	{"decodeDateType", "", codecHelper("DateType", "decode"), []string{"DateType"}},
+	{"encodeDateType", "", codecHelper("DateType", "encode"), []string{"DateType"}},
+	{"decodeBigIntType", "", codecHelper("BigIntType", "decode"), []string{"BigIntType"}},
+	{"encodeBigIntType", "", codecHelper("BigIntType", "encode"), []string{"BigIntType"}},
+	{"decodeBigIntStringType", "", codecHelper("BigIntStringType", "decode"), []string{"BigIntStringType"}},
+	{"encodeBigIntStringType", "", codecHelper("BigIntStringType", "encode"), []string{"BigIntStringType"}},
+	{"DeepPartial", "", deepPartialHelper, nil},
+}
+
+var (
+	aliasMu sync.RWMutex
+	// aliases which were added with RegisterAlias
+	customAliases []alias
+	// go types which are converted to the custom aliases
+	aliasTypes = make(map[r.Type]string)
+)
+
+// RegisterAlias registers an additional type alias, which is declared in
+// the header of the generated files (only if it is used). The Go types of
+// the optional values are converted to the alias.
+//
+// Example
+//
+//	gut.RegisterAlias("DecimalType", "string", decimal.Decimal{})
+//
+//	// type Price struct { Amount decimal.Decimal } is converted to
+//	// export interface Price { Amount: DecimalType }
+func RegisterAlias(name string, tsType string, values ...interface{}) {
+	if !isValidTypeName(name) {
+		panic(fmt.Sprintf("Invalid typescript alias name was provided! %v", name))
+	}
+	for _, a := range builtinAliases {
+		if a.name == name {
+			panic(fmt.Sprintf("%v is a builtin alias and can be changed only with the Settings", name))
+		}
+	}
+
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+
+	a := alias{name, func(Settings) string { return tsType }}
+
+	replaced := false
+	for i := range customAliases {
+		if customAliases[i].name == name {
+			customAliases[i] = a
+			replaced = true
+		}
+	}
+	if !replaced {
+		customAliases = append(customAliases, a)
+	}
+
+	for _, v := range values {
+		aliasTypes[r.TypeOf(v)] = name
+	}
+}
+
+// UseAlias converts the Go types of the passed in values to an already
+// existing (builtin or registered) alias. Useful for wrapper types which
+// are marshalled in the same way as the type of the builtin alias.
+//
+// Example
+//
+//	// type Duration struct{ time.Duration } is marshalled as "1h30m"
+//	gut.UseAlias("DurationType", Duration{})
+func UseAlias(name string, values ...interface{}) {
+	exists := false
+	for _, a := range allAliases() {
+		if a.name == name {
+			exists = true
+		}
+	}
+	if !exists {
+		panic(fmt.Sprintf("The alias %v does not exist!", name))
+	}
+
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+
+	for _, v := range values {
+		aliasTypes[r.TypeOf(v)] = name
+	}
+}
+
+// registeredAlias returns the name of the alias to which the type
+// should be converted, if it was registered with RegisterAlias.
+func registeredAlias(typ r.Type) (string, bool) {
+	aliasMu.RLock()
+	defer aliasMu.RUnlock()
+
+	name, ok := aliasTypes[typ]
+	return name, ok
+}
+
+// allAliases returns the builtin and the registered aliases.
+func allAliases() []alias {
+	aliasMu.RLock()
+	defer aliasMu.RUnlock()
+
+	return append(append([]alias(nil), builtinAliases...), customAliases...)
+}
+
+// usage holds the aliases and the helpers which are used
+// by the declarations, from which the header is created.
+type usage struct {
+	aliases map[string]bool
+	helpers map[string]bool
+}
+
+func newUsage() usage {
+	return usage{aliases: make(map[string]bool), helpers: make(map[string]bool)}
+}
+
+// contentUsage returns the aliases and the helpers which are referenced in
+// the content (like the strings returned by Convert, which may have been
+// changed afterwards). The property names and the names which are declared
+// in the content are not references.
+func contentUsage(content string) usage {
+	declared := make(map[string]bool)
+	for _, match := range declaredNameExp.FindAllStringSubmatch(content, -1) {
+		declared[match[1]] = true
+	}
+
+	used := newUsage()
+	for _, a := range allAliases() {
+		if !declared[a.name] && referencesName(content, a.name) {
+			used.aliases[a.name] = true
+		}
+	}
+	for _, h := range helpers {
+		// the helpers are called (helper(...) or helper<T>(...)), or
+		// used as generic types (DeepPartial<T>)
+		called := regexp.MustCompile(`(^|[^\w$."'])` + h.name + `(<[^>]*>)?\(|(^|[^\w$."'])` + h.name + `<`).MatchString(content)
+		if called && !declared[h.name] {
+			used.helpers[h.name] = true
+		}
+	}
+	return used
+}
+
+// matches the names of the declarations in the content
+var declaredNameExp = regexp.MustCompile(`(?:^|[^\w$.])(?:interface|type|class|function|const|let|var|enum|namespace)\s+([A-Za-z_$][\w$]*)`)
+
+// referencesName checks if the identifier is used in the content, but not
+// as a part of a longer identifier, a property access, a string or as the
+// name of a property ({ DateType: string }).
+func referencesName(content string, name string) bool {
+	for i := strings.Index(content, name); i >= 0; {
+		end := i + len(name)
+		if (i == 0 || !isIdentifierByte(content[i-1]) && !strings.ContainsRune(`."'`, rune(content[i-1]))) &&
+			(end == len(content) || !isIdentifierByte(content[end])) &&
+			!isPropertyName(content[end:]) {
+			return true
+		}
+
+		next := strings.Index(content[end:], name)
+		if next < 0 {
+			break
+		}
+		i = end + next
+	}
+	return false
+}
+
+// isPropertyName checks if the rest of the content starts with the
+// separator of a property name and its type (": " or "?: ").
+func isPropertyName(rest string) bool {
+	rest = strings.TrimLeft(rest, " \t")
+	rest = strings.TrimPrefix(rest, "?")
+	return strings.HasPrefix(strings.TrimLeft(rest, " \t"), ":")
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// aliasQualifier returns the prefix and the namespace from the
+// settings, which are added to the names of the aliases.
+func aliasQualifier(s Settings) string {
+	if s.AliasNamespace != "" {
+		return s.AliasNamespace + "." + s.AliasPrefix
+	}
+	return s.AliasPrefix
+}
+
+// qualifiedAlias returns the name of the alias, which is used in the
+// declarations and the helpers (e.g. "gut.ApiDateType").
+func qualifiedAlias(name string, s Settings) string {
+	return aliasQualifier(s) + name
+}
+
+// createHeader returns the header of the content (returned by Convert),
+// which does not know the settings, so it uses the unqualified names of
+// the aliases.
+func createHeader(s Settings, content string) string {
+	return usedHeader(s, contentUsage(content), true)
+}
+
+// usedHeader returns the header of the generated file, which holds the
+// first line, the aliases and the helpers which were used by the emitter.
+// If local is set, the unqualified names of the aliases are declared
+// as well (not exported), so the converted content can use them.
+func usedHeader(s Settings, used usage, local bool) string {
+	sb := strings.Builder{}
+
+	// Append first line if exists
+	if s.FirstLine != "" {
+		sb.WriteString(s.FirstLine)
+	}
+
+	aliases := make(map[string]bool)
+	for name := range used.aliases {
+		aliases[name] = true
+	}
+
+	code := strings.Builder{}
+	for _, h := range helpers {
+		if used.helpers[h.name] || (s.Helpers && h.alias != "" && used.aliases[h.alias]) {
+			code.WriteString(h.code(s))
+			code.WriteString("\n")
+
+			// the helpers may use aliases which are not used by the declarations
+			for _, name := range h.uses {
+				aliases[name] = true
+			}
+		}
+	}
+
+	declarations := strings.Builder{}
+	unqualified := strings.Builder{}
+	for _, a := range allAliases() {
+		if !aliases[a.name] {
+			continue
+		}
+
+		tsType := a.resolve(s)
+
+		if s.AliasNamespace != "" {
+			declarations.WriteString("  ")
+		}
+		declarations.WriteString(fmt.Sprintf("export type %s%s = %s\n", s.AliasPrefix, a.name, tsType))
+
+		if qualified := qualifiedAlias(a.name, s); local && qualified != a.name {
+			unqualified.WriteString(fmt.Sprintf("type %s = %s\n", a.name, qualified))
+		}
+	}
+
+	if declarations.Len() == 0 && code.Len() == 0 {
+		return sb.String()
+	}
+
+	if declarations.Len() > 0 {
+		if s.AliasNamespace != "" {
+			sb.WriteString(fmt.Sprintf("export namespace %s {\n%s}\n", s.AliasNamespace, declarations.String()))
+		} else {
+			sb.WriteString(declarations.String())
+		}
+		sb.WriteString(unqualified.String())
+	}
+
+	if code.Len() > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(code.String())
+	}
+
+	// seperate type definitions from the generated interfaces
+	sb.WriteString("\n\n")
+
+	return sb.String()
+}
+
+// resolve returns the typescript type of the alias,
+// taking into account the Settings.Aliases overrides.
+func (a alias) resolve(s Settings) string {
+	if custom, ok := s.Aliases[a.name]; ok {
+		return custom
+	}
+	return a.tsType(s)
+}
+
+// aliasType returns the typescript type of the alias with the name
+func aliasType(name string, s Settings) string {
+	for _, a := range allAliases() {
+		if a.name == name {
+			return a.resolve(s)
+		}
+	}
+	return ""
+}
+
+func valueOr(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,237 @@
+package gut
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/tompston/gut/types"
+)
+
+type Decimal struct {
+	value string
+}
+
+type StructWithDecimal struct {
+	Price    Decimal   `json:"price"`
+	Discount *Decimal  `json:"discount"`
+	Prices   []Decimal `json:"prices"`
+}
+
+func TestCreateHeader(t *testing.T) {
+	RegisterAlias("DecimalType", "string", Decimal{})
+
+	type test struct {
+		content  string
+		settings Settings
+		expected string
+	}
+
+	tests := []test{
+		{
+			// aliases which are not used are not declared
+			content:  Convert(SimpleStruct{}),
+			expected: ``,
+		},
+		{
+			content:  Convert(Employees{}),
+			settings: Settings{FirstLine: "// generated\n"},
+			expected: `
+			// generated
+			export type UuidType = string`,
+		},
+		{
+			content: Convert(StructWithMaps{}) + Convert(SimpleStructWithTimeFields{}),
+			expected: `
+			export type BigIntType = number
+			export type DateType = Date`,
+		},
+		{
+			content:  Convert(StructWithDecimal{}),
+			expected: `export type DecimalType = string`,
+		},
+		{
+			content:  Convert(StructWithDecimal{}) + Convert(SimpleStructWithTimeFields{}),
+			settings: Settings{AliasPrefix: "Api", DateType: "string", Aliases: map[string]string{"DecimalType": "number"}},
+			expected: `
+			export type ApiDateType = string
+			export type ApiDecimalType = number
+			type DateType = ApiDateType
+			type DecimalType = ApiDecimalType`,
+		},
+		{
+			content:  Convert(SimpleStructWithTimeFields{}),
+			settings: Settings{AliasNamespace: "gut"},
+			expected: `
+			export namespace gut {
+				export type DateType = Date
+			}
+			type DateType = gut.DateType`,
+		},
+	}
+
+	for _, tc := range tests {
+		if header := createHeader(tc.settings, tc.content); stripSpaces(header) != stripSpaces(tc.expected) {
+			t.Fatalf("expected: %v\n, got: %v\n", tc.expected, header)
+		}
+	}
+}
+
+func TestRegisteredAliasConversion(t *testing.T) {
+	RegisterAlias("DecimalType", "string", Decimal{})
+
+	expected := `
+	export interface StructWithDecimal {
+		price: DecimalType
+		discount: DecimalType
+		prices: DecimalType[]
+	}`
+	if generated := Convert(StructWithDecimal{}); stripSpaces(generated) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+}
+
+func TestQualifiedAliases(t *testing.T) {
+	type DateType struct {
+		Value string `json:"value"`
+	}
+	type Event struct {
+		UuidType  string    `json:"UuidType"`
+		Date      DateType  `json:"date"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	settings := Settings{AliasPrefix: "Api", AliasNamespace: "gut"}
+
+	// the properties and the declarations which have the names of
+	// the aliases are not renamed, and UuidType is not declared
+	reg := NewRegistry().Add(DateType{}, Type{Module: "events"}).Add(Event{}, Type{Module: "events"})
+	files, err := reg.files(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+	export namespace gut {
+		export type ApiDateType = Date
+	}
+
+	export interface DateType {
+		value: string
+	}
+
+	export interface Event {
+		UuidType: string
+		date: DateType
+		created_at: gut.ApiDateType
+	}`
+	if stripSpaces(files["events.ts"]) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, files["events.ts"])
+	}
+
+	// the converted string keeps the plain names, which are declared locally
+	var buf strings.Builder
+	if err := Render(&buf, Convert(Event{}), settings); err != nil {
+		t.Fatal(err)
+	}
+	if !containsAll(buf.String(), "export type ApiDateType = Date\n", "type DateType = gut.ApiDateType\n", "  UuidType: string\n", "  created_at: DateType\n") ||
+		strings.Contains(buf.String(), "ApiUuidType") {
+		t.Fatalf("expected the plain aliases to be declared, got: %v\n", buf.String())
+	}
+}
+
+func TestContentHeader(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		// the content may be changed after it was converted
+		{strings.TrimSpace(Convert(SimpleStructWithTimeFields{})), "export type DateType = Date\n"},
+		{"export interface X { t: DateType }", "export type DateType = Date\n"},
+		{"export type Ids = UuidType[]", "export type UuidType = string\n"},
+		// property names, strings and declarations are not references
+		{"export interface X { UuidType: string, \"DateType\"?: number }", ""},
+		{"export interface DateType { value: string }", ""},
+	}
+
+	for _, tt := range tests {
+		if header := createHeader(Settings{}, tt.content); strings.TrimSpace(header) != strings.TrimSpace(tt.expected) {
+			t.Errorf("expected: %q\n, got: %q\n", tt.expected, header)
+		}
+	}
+}
+
+func TestDurationHeader(t *testing.T) {
+	content := Convert(StructWithTimeDurationField{})
+
+	type test struct {
+		settings Settings
+		expected string
+	}
+
+	tests := []test{
+		{
+			expected: `export type DurationType = number`,
+		},
+		{
+			settings: Settings{DurationFormat: DurationString},
+			expected: `export type DurationType = string`,
+		},
+		{
+			settings: Settings{DurationFormat: DurationISO8601, DurationType: "`P${string}`"},
+			expected: "export type DurationType = `P${string}`",
+		},
+		{
+			settings: Settings{DurationFormat: DurationSeconds, Helpers: true},
+			expected: `
+			export type DurationType = number
+
+			export function parseDuration(value: DurationType): number {
+				return value * 1000
+			}
+
+			export function formatDuration(ms: number): DurationType {
+				return ms / 1000
+			}`,
+		},
+	}
+
+	for _, tc := range tests {
+		if header := createHeader(tc.settings, content); stripSpaces(header) != stripSpaces(tc.expected) {
+			t.Fatalf("expected: %v\n, got: %v\n", tc.expected, header)
+		}
+	}
+
+	// the helpers are also declared if they are called in the content
+	header := createHeader(Settings{}, "export const timeout = parseDuration(1000)")
+	if !strings.Contains(header, "export type DurationType = number") || !strings.Contains(header, "export function parseDuration(") {
+		t.Fatalf("expected the parseDuration helper in the header, got: %v\n", header)
+	}
+}
+
+func TestErrorTypeHeader(t *testing.T) {
+	content := Convert(Plugin{})
+
+	tests := []struct {
+		settings Settings
+		expected string
+	}{
+		{expected: `export type ErrorType = Error`},
+		{settings: Settings{ErrorType: "{ message: string }"}, expected: `export type ErrorType = { message: string }`},
+	}
+
+	for _, tc := range tests {
+		if header := createHeader(tc.settings, content); !containsAll(header, tc.expected) {
+			t.Fatalf("expected: %v\n, got: %v\n", tc.expected, header)
+		}
+	}
+}
+
+func containsAll(s string, substrings ...string) bool {
+	for _, sub := range substrings {
+		if !strings.Contains(s, sub) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package gut
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tompston/gut/ir"
+)
+
+// Int64Mode defines how the int64 / uint64 values are represented in
+// typescript. The json numbers which are bigger than
+// Number.MAX_SAFE_INTEGER lose precision when they are parsed with
+// JSON.parse, so depending on the size of the values, they can be
+// kept as numbers, marshalled as strings or revived as bigints.
+//
+//	| Int64Mode   | BigIntType | BigIntStringType (",string" tag) |
+//	|-------------|------------|----------------------------------|
+//	| Int64Number | number     | string                           |
+//	| Int64String | string     | string                           |
+//	| Int64BigInt | bigint     | bigint                           |
+type Int64Mode string
+
+const (
+	// The values are json numbers, which is how encoding/json
+	// marshals them by default.
+	Int64Number Int64Mode = "number"
+	// The values are converted to bigint after parsing, with the
+	// revive<Name> functions which are emitted if Type.Reviver is set.
+	// Values bigger than Number.MAX_SAFE_INTEGER should be marshalled
+	// as strings (",string" json option), so that they are exact.
+	Int64BigInt Int64Mode = "bigint"
+	// The values are marshalled as strings. Meant to be paired with
+	// the ",string" json option (or custom marshallers).
+	Int64String Int64Mode = "string"
+)
+
+func int64Mode(s Settings) Int64Mode {
+	switch s.Int64Mode {
+	case Int64Number, Int64BigInt, Int64String:
+		return s.Int64Mode
+	case "":
+		return Int64Number
+	default:
+		panic(fmt.Sprintf("Invalid int64 mode was provided! %v", s.Int64Mode))
+	}
+}
+
+// tsType returns the type of the int64 / uint64 values
+func (m Int64Mode) tsType() string {
+	return string(m)
+}
+
+// stringTSType returns the type of the int64 / uint64
+// values which have the ",string" json option
+func (m Int64Mode) stringTSType() string {
+	if m == Int64BigInt {
+		return "bigint"
+	}
+	return "string"
+}
+
+// reviverFunction returns the paths of the int64 / uint64 values in
+// the struct and a function which converts them to bigint.
+func (e *tsEmitter) reviverFunction(decl *ir.Decl) string {
+	paths := bigIntPaths(&ir.Type{Kind: ir.Object, Fields: decl.Fields}, nil, map[*ir.Decl]bool{decl: true})
+
+	quoted := make([]string, 0, len(paths))
+	for _, path := range paths {
+		keys := make([]string, 0, len(path))
+		for _, key := range path {
+			keys = append(keys, fmt.Sprintf("%q", key))
+		}
+		quoted = append(quoted, fmt.Sprintf("[%s]", strings.Join(keys, ", ")))
+	}
+
+	typeName := decl.Name
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("export const %sBigIntPaths: string[][] = [%s]\n\n", typeName, strings.Join(quoted, ", ")))
+	sb.WriteString(fmt.Sprintf("export function revive%s(value: any): %s {\n", typeName, typeName))
+	sb.WriteString(fmt.Sprintf("  return %s<%s>(value, %sBigIntPaths)\n", e.helper("reviveBigInts"), typeName, typeName))
+	sb.WriteString("}\n\n")
+	return sb.String()
+}
+
+// bigIntPaths returns the json paths of all of the int64 / uint64 values
+// in the type. Elements of arrays and values of maps are marked with "*".
+func bigIntPaths(typ *ir.Type, prefix []string, visiting map[*ir.Decl]bool) [][]string {
+	var paths [][]string
+
+	switch typ.Kind {
+	case ir.Option:
+		return bigIntPaths(typ.Elem, prefix, visiting)
+
+	case ir.Array, ir.Map:
+		return bigIntPaths(typ.Elem, append(prefix[:len(prefix):len(prefix)], "*"), visiting)
+
+	case ir.Primitive:
+		if typ.Primitive == ir.Int64 {
+			return [][]string{prefix}
+		}
+
+	case ir.Reference:
+		if visiting[typ.Decl] {
+			return nil
+		}
+		visiting[typ.Decl] = true
+		defer delete(visiting, typ.Decl)
+
+		if typ.Decl.Kind == ir.AliasDecl {
+			return bigIntPaths(typ.Decl.Type, prefix, visiting)
+		}
+		return bigIntPaths(&ir.Type{Kind: ir.Object, Fields: typ.Decl.Fields}, prefix, visiting)
+
+	case ir.Object:
+		for _, field := range typ.Fields {
+			if field.Inline {
+				paths = append(paths, bigIntPaths(field.Type, prefix, visiting)...)
+			} else {
+				path := append(prefix[:len(prefix):len(prefix)], field.JSONName)
+				paths = append(paths, bigIntPaths(field.Type, path, visiting)...)
+			}
+		}
+	}
+
+	return paths
+}
+
+func reviveBigIntsHelper(Settings) string {
+	return `export function reviveBigInts<T>(value: any, paths: string[][]): T {
+  const revive = (v: any, path: string[]): any => {
+    if (v === null || v === undefined) return v
+    if (path.length === 0) return typeof v === "number" || typeof v === "string" ? BigInt(v) : v
+    const [key, ...rest] = path
+    if (typeof v !== "object") return v
+    if (key !== "*") {
+      if (key in v) v[key] = revive(v[key], rest)
+      return v
+    }
+    if (Array.isArray(v)) return v.map((item) => revive(item, rest))
+    for (const k of Object.keys(v)) v[k] = revive(v[k], rest)
+    return v
+  }
+  for (const path of paths) value = revive(value, path)
+  return value as T
+}
+`
+}
@@ -0,0 +1,77 @@
+package gut
+
+import (
+	"testing"
+)
+
+type StructWithInt64s struct {
+	ID       int64            `json:"id,string"`
+	Count    uint64           `json:"count"`
+	Ratio    float64          `json:"ratio,string"`
+	Items    []Int64Item      `json:"items"`
+	Totals   map[string]int64 `json:"totals"`
+	Optional *int64           `json:"optional,omitempty"`
+	Int64Item
+}
+
+type Int64Item struct {
+	Amount int64 `json:"amount"`
+	Label  string
+}
+
+func TestInt64Conversion(t *testing.T) {
+	expected := `
+	export interface StructWithInt64s {
+		id: BigIntStringType
+		count: BigIntType
+		ratio: string
+		items: {
+			amount: BigIntType
+			Label: string
+		}[]
+		totals: {[key: string]: BigIntType}
+		optional?: BigIntType
+		amount: BigIntType
+		Label: string
+	}
+
+	export const StructWithInt64sBigIntPaths: string[][] = [
+		["id"], ["count"], ["items", "*", "amount"], ["totals", "*"], ["optional"], ["amount"]
+	]
+
+	export function reviveStructWithInt64s(value: any): StructWithInt64s {
+		return reviveBigInts<StructWithInt64s>(value, StructWithInt64sBigIntPaths)
+	}`
+
+	if generated := Convert(StructWithInt64s{}, Type{Reviver: true}); stripSpaces(generated) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+}
+
+func TestInt64Modes(t *testing.T) {
+	content := Convert(StructWithInt64s{})
+
+	tests := map[Int64Mode]string{
+		"": `
+			export type BigIntType = number
+			export type BigIntStringType = string`,
+		Int64String: `
+			export type BigIntType = string
+			export type BigIntStringType = string`,
+		Int64BigInt: `
+			export type BigIntType = bigint
+			export type BigIntStringType = bigint`,
+	}
+
+	for mode, expected := range tests {
+		if header := createHeader(Settings{Int64Mode: mode}, content); stripSpaces(header) != stripSpaces(expected) {
+			t.Fatalf("expected: %v\n, got: %v\n", expected, header)
+		}
+	}
+
+	// the reviveBigInts helper is declared, if the revivers are used
+	header := createHeader(Settings{Int64Mode: Int64BigInt}, Convert(StructWithInt64s{}, Type{Reviver: true}))
+	if !containsAll(header, "export type BigIntType = bigint", "export function reviveBigInts<T>(value: any, paths: string[][]): T {") {
+		t.Fatalf("expected the reviveBigInts helper in the header, got: %v\n", header)
+	}
+}
@@ -0,0 +1,442 @@
+package ir
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	durationType  = reflect.TypeOf(time.Duration(0))
+	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+	jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Builder creates a Graph from reflected Go types. The structs which
+// should be emitted on their own have to be declared first, then Build
+// converts their fields. Structs which are not declared are inlined
+// as objects.
+//
+// Example
+//
+//	b := ir.NewBuilder()
+//	b.Declare(reflect.TypeOf(User{}), "User")
+//	b.Declare(reflect.TypeOf(Comment{}), "Comment")
+//	graph, err := b.Build()
+type Builder struct {
+	// Optional func which returns the name of the custom
+	// alias to which the Go type should be converted.
+	Alias func(t reflect.Type) (name string, ok bool)
+	// Optional func which returns the name of the anonymous struct, which
+	// is held by the field of the parent declaration (directly or as the
+	// element of a pointer, slice, array or map). If ok is true, the struct
+	// is declared under the name, instead of being inlined as an object.
+	Anonymous func(parent *Decl, field reflect.StructField) (name string, ok bool)
+	// Optional func which returns the name under which the named type (which
+	// is not a struct, like type UserID int64) is declared. If ok is true, the
+	// types which hold it reference the declaration, instead of converting
+	// the underlying type in place.
+	Named func(t reflect.Type) (name string, ok bool)
+
+	graph *Graph
+	// declarations of the structs, by their Go type
+	declared map[reflect.Type]*Decl
+	// declared structs whose fields are not built yet
+	pending []*Decl
+	// structs which are currently being inlined
+	building map[reflect.Type]bool
+	// declaration whose fields are currently built
+	parent *Decl
+	errs   []string
+}
+
+// NewBuilder returns a Builder with an empty graph.
+func NewBuilder() *Builder {
+	return &Builder{
+		graph:    &Graph{},
+		declared: make(map[reflect.Type]*Decl),
+		building: make(map[reflect.Type]bool),
+	}
+}
+
+// FromValues returns the graph in which the types of the
+// passed in structs are declared under their Go names.
+func FromValues(values ...interface{}) (*Graph, error) {
+	b := NewBuilder()
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		b.Declare(t, TypeName(t))
+	}
+	return b.Build()
+}
+
+// Declare adds the declaration of the struct to the graph. Other types
+// which hold the struct reference the declaration, instead of inlining it.
+// If the struct is declared more than once, the types reference the
+// first declaration.
+func (b *Builder) Declare(t reflect.Type, name string) *Decl {
+	if t == nil || t.Kind() != reflect.Struct {
+		b.errorf("only structs can be declared, got %v", t)
+		return &Decl{Name: name, Go: t}
+	}
+
+	d := &Decl{Name: name, Kind: StructDecl, PkgPath: t.PkgPath(), Go: t}
+	if _, ok := b.declared[t]; !ok {
+		b.declared[t] = d
+	}
+	b.graph.Decls = append(b.graph.Decls, d)
+	b.pending = append(b.pending, d)
+	return d
+}
+
+// DeclareAlias adds a named alias of the type to the graph.
+// The Go type is optional and is used only for its package path.
+func (b *Builder) DeclareAlias(name string, typ *Type, goType reflect.Type) *Decl {
+	d := &Decl{Name: name, Kind: AliasDecl, Type: typ, Go: goType}
+	if goType != nil {
+		d.PkgPath = goType.PkgPath()
+	}
+	b.graph.Decls = append(b.graph.Decls, d)
+	return d
+}
+
+// DeclareUnion adds the declaration of a union of the variants to the
+// graph. Types which hold the Go type (usually an interface, which is
+// implemented by the variants) reference the declaration.
+func (b *Builder) DeclareUnion(t reflect.Type, name string, discriminator string, variants ...*Decl) *Decl {
+	union := &Type{Kind: Union, Discriminator: discriminator, Go: t}
+	for _, v := range variants {
+		union.Variants = append(union.Variants, RefTo(v))
+	}
+
+	d := b.DeclareAlias(name, union, t)
+	if _, ok := b.declared[t]; !ok {
+		b.declared[t] = d
+	}
+	return d
+}
+
+// DeclareNamed adds the declaration of the named type (which is not a
+// struct) to the graph, as an alias of its underlying type. Other types
+// which hold the Go type reference the declaration. Named arrays of 16
+// bytes (type UserID uuid.UUID) are converted to UUIDs, if they implement
+// encoding.TextMarshaler (the methods of uuid.UUID are not inherited, so
+// without them the array is marshalled as numbers).
+func (b *Builder) DeclareNamed(t reflect.Type, name string) *Decl {
+	if d, ok := b.declared[t]; ok && d.Kind == AliasDecl && d.Name == name {
+		return d
+	}
+
+	var typ *Type
+	if t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8 && t.Implements(textMarshaler) {
+		typ = &Type{Kind: Primitive, Primitive: UUID, Go: t}
+	} else {
+		typ = b.convert(t)
+	}
+
+	d := b.DeclareAlias(name, typ, t)
+	if _, ok := b.declared[t]; !ok {
+		b.declared[t] = d
+	}
+	return d
+}
+
+// DeclareEnum adds the declaration of the enum (an alias of the union of
+// the literal values, with the optional labels of the values) to the graph.
+// The values are stored as Type.Literal, so they should be strings or
+// float64. Types which hold the Go type reference the declaration.
+func (b *Builder) DeclareEnum(t reflect.Type, name string, values []interface{}, labels []string) *Decl {
+	union := &Type{Kind: Union, Go: t}
+	for _, v := range values {
+		union.Variants = append(union.Variants, &Type{Kind: Literal, Literal: v, Go: t})
+	}
+
+	d := b.DeclareAlias(name, union, t)
+	d.Labels = labels
+	if _, ok := b.declared[t]; !ok {
+		b.declared[t] = d
+	}
+	return d
+}
+
+// AddEndpoint adds the endpoint to the graph.
+func (b *Builder) AddEndpoint(ep *Endpoint) {
+	b.graph.Endpoints = append(b.graph.Endpoints, ep)
+}
+
+// AddService adds the service to the graph.
+func (b *Builder) AddService(s *Service) {
+	b.graph.Services = append(b.graph.Services, s)
+}
+
+// Decl returns the declaration of the Go type, if it was declared.
+func (b *Builder) Decl(t reflect.Type) *Decl {
+	return b.declared[t]
+}
+
+// Build converts the fields of the declared structs and returns the graph.
+func (b *Builder) Build() (*Graph, error) {
+	// building the fields may declare new structs (recursive types)
+	for len(b.pending) > 0 {
+		d := b.pending[0]
+		b.pending = b.pending[1:]
+		b.parent = d
+		d.Fields = b.fields(d.Go)
+	}
+	b.parent = nil
+
+	if len(b.errs) > 0 {
+		return b.graph, fmt.Errorf("ir: %s", strings.Join(b.errs, "; "))
+	}
+	return b.graph, nil
+}
+
+// Type converts the Go type. Declared structs are referenced,
+// while other structs are converted to objects.
+func (b *Builder) Type(t reflect.Type) *Type {
+	if b.Alias != nil {
+		if name, ok := b.Alias(t); ok {
+			return &Type{Kind: Alias, Alias: name, Go: t}
+		}
+	}
+
+	if t == durationType {
+		return &Type{Kind: Primitive, Primitive: Duration, Go: t}
+	}
+	if t == errorType {
+		return &Type{Kind: Primitive, Primitive: Error, Go: t}
+	}
+
+	if d, ok := b.declared[t]; ok {
+		return RefTo(d)
+	}
+
+	if b.Named != nil && t.Name() != "" && t.Kind() != reflect.Struct {
+		if name, ok := b.Named(t); ok {
+			return RefTo(b.DeclareNamed(t, name))
+		}
+	}
+
+	return b.convert(t)
+}
+
+// convert converts the Go type based on its kind.
+func (b *Builder) convert(t reflect.Type) *Type {
+	switch t.Kind() {
+	case reflect.Struct:
+		if t == timeType {
+			return &Type{Kind: Primitive, Primitive: Time, Go: t}
+		}
+		if d, ok := b.declared[t]; ok {
+			return RefTo(d)
+		}
+		if b.building[t] {
+			// the struct holds itself, so it has to be declared
+			return RefTo(b.Declare(t, TypeName(t)))
+		}
+		return b.object(t)
+
+	case reflect.Slice:
+		if IsBytes(t) {
+			return &Type{Kind: Primitive, Primitive: String, Go: t}
+		}
+		return &Type{Kind: Array, Elem: b.Type(t.Elem()), Go: t}
+
+	case reflect.Array:
+		if t.Name() == "UUID" {
+			return &Type{Kind: Primitive, Primitive: UUID, Go: t}
+		}
+		return &Type{Kind: Array, Elem: b.Type(t.Elem()), Go: t}
+
+	case reflect.Map:
+		return &Type{Kind: Map, Key: b.Type(t.Key()), Elem: b.Type(t.Elem()), Go: t}
+
+	case reflect.Ptr:
+		return &Type{Kind: Option, Elem: b.Type(t.Elem()), Go: t}
+
+	case reflect.String:
+		return &Type{Kind: Primitive, Primitive: String, Go: t}
+
+	case reflect.Bool:
+		return &Type{Kind: Primitive, Primitive: Boolean, Go: t}
+
+	case
+		reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Type{Kind: Primitive, Primitive: Number, Go: t}
+
+	case reflect.Int64, reflect.Uint64:
+		return &Type{Kind: Primitive, Primitive: Int64, Go: t}
+
+	case reflect.Func:
+		return b.function(t)
+
+	case reflect.Chan, reflect.UnsafePointer:
+		b.errorf("%v values can not be converted (%v)", t.Kind(), t)
+		return &Type{Kind: Primitive, Primitive: Any, Go: t}
+
+	default:
+		return &Type{Kind: Primitive, Primitive: Any, Go: t}
+	}
+}
+
+// function converts the parameters and the results of the func.
+func (b *Builder) function(t reflect.Type) *Type {
+	fn := &Type{Kind: Func, Variadic: t.IsVariadic(), Go: t}
+	for i := 0; i < t.NumIn(); i++ {
+		fn.Params = append(fn.Params, b.Type(t.In(i)))
+	}
+	for i := 0; i < t.NumOut(); i++ {
+		fn.Results = append(fn.Results, b.Type(t.Out(i)))
+	}
+	return fn
+}
+
+// object converts the struct into an anonymous object.
+func (b *Builder) object(t reflect.Type) *Type {
+	b.building[t] = true
+	defer delete(b.building, t)
+
+	return &Type{Kind: Object, Fields: b.fields(t), Go: t}
+}
+
+// fields converts the exported fields of the struct.
+func (b *Builder) fields(t reflect.Type) []*Field {
+	var fields []*Field
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" { // Skip unexported fields
+			continue
+		}
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		f := &Field{
+			Name:     sf.Name,
+			JSONName: sf.Name,
+			Optional: isOptional(sf.Type, tag),
+			// encoding/json flattens the embedded structs without a json name
+			Inline:      hasOption(tag, "inline") || sf.Anonymous && name == "",
+			Stringified: hasOption(tag, "string"),
+			Tag:         sf.Tag,
+		}
+		if name != "" {
+			f.JSONName = name
+		}
+
+		embedded := sf.Type
+		if embedded.Kind() == reflect.Ptr {
+			embedded = embedded.Elem()
+		}
+
+		if f.Inline && embedded.Kind() == reflect.Struct && embedded != timeType {
+			// the fields of inlined structs are always copied
+			// into the parent, even if the struct is declared
+			f.Type = b.object(embedded)
+		} else {
+			f.Inline = false
+			b.declareAnonymous(sf)
+			f.Type = b.Type(sf.Type)
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// declareAnonymous declares the anonymous struct of the field, if
+// the Anonymous func returns its name.
+func (b *Builder) declareAnonymous(sf reflect.StructField) {
+	if b.Anonymous == nil || b.parent == nil {
+		return
+	}
+
+	t := sf.Type
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.Name() != "" {
+		return
+	}
+	// structs with the same shape have the same type, so they are reused
+	if _, ok := b.declared[t]; ok {
+		return
+	}
+
+	if name, ok := b.Anonymous(b.parent, sf); ok {
+		d := b.Declare(t, name)
+		d.PkgPath = b.parent.PkgPath
+		d.Parent = b.parent
+	}
+}
+
+func (b *Builder) errorf(format string, args ...interface{}) {
+	b.errs = append(b.errs, fmt.Sprintf(format, args...))
+}
+
+// isOptional reports whether encoding/json can omit the field, based on
+// its kind and the options of the json tag:
+//
+//	kind                              omitempty    omitzero
+//	bool, numbers, string             optional     optional
+//	pointer, interface, slice, map    optional     optional
+//	func (never encoded)              optional     optional
+//	array                             if len = 0   optional
+//	struct (including time.Time)      required     optional
+//
+// omitempty never omits struct values, while omitzero (Go 1.24) omits the
+// zero values of every kind (or the values whose IsZero method returns true).
+func isOptional(t reflect.Type, tag string) bool {
+	if hasOption(tag, "omitzero") {
+		return true
+	}
+	if !hasOption(tag, "omitempty") {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return false
+	case reflect.Array:
+		return t.Len() == 0
+	default:
+		return true
+	}
+}
+
+// hasOption checks if the json tag has the option (like "omitempty")
+func hasOption(tag string, option string) bool {
+	for _, opt := range strings.Split(tag, ",")[1:] {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBytes checks if the Go type is a slice of bytes, which encoding/json
+// marshals as a base64 string (unless the type or its elements implement
+// json.Marshaler or encoding.TextMarshaler).
+func IsBytes(t reflect.Type) bool {
+	if t == nil || t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+	for _, m := range []reflect.Type{jsonMarshaler, textMarshaler} {
+		if t.Implements(m) || reflect.PtrTo(t.Elem()).Implements(m) {
+			return false
+		}
+	}
+	return true
+}
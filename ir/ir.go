@@ -0,0 +1,270 @@
+// Package ir holds the intermediate representation (IR) of the Go types
+// which are converted by gut.
+//
+// A Graph is a list of declarations (named types which are emitted on
+// their own, like typescript interfaces) and every declaration is
+// described by a tree of Types. Types which reference another
+// declaration point to it with a Reference, instead of copying it.
+//
+// The graph is usually created from reflected Go types with a Builder,
+// but it is a plain data structure, so it can also be created by hand
+// (for example from types which are loaded from the source code). The
+// typescript output of gut is emitted from the graph, so custom emitters
+// and analyzers can be written against the same model.
+package ir
+
+import (
+	"reflect"
+)
+
+// Kind is the kind of a Type.
+type Kind int
+
+const (
+	Invalid Kind = iota
+	// Primitive value (Type.Primitive)
+	Primitive
+	// Custom alias which was registered for the Go type (Type.Alias)
+	Alias
+	// Reference to a declaration in the graph (Type.Decl)
+	Reference
+	// Array (or slice) of Type.Elem
+	Array
+	// Map with the Type.Key keys and the Type.Elem values
+	Map
+	// Anonymous object, which holds the Type.Fields. Structs
+	// which are not declared in the graph are inlined as objects.
+	Object
+	// Value of Type.Elem, which can also be null (pointers)
+	Option
+	// One of the Type.Variants
+	Union
+	// Constant value (Type.Literal)
+	Literal
+	// Function with the Type.Params and the Type.Results
+	Func
+)
+
+var kindNames = map[Kind]string{
+	Invalid:   "invalid",
+	Primitive: "primitive",
+	Alias:     "alias",
+	Reference: "reference",
+	Array:     "array",
+	Map:       "map",
+	Object:    "object",
+	Option:    "option",
+	Union:     "union",
+	Literal:   "literal",
+	Func:      "func",
+}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// PrimitiveKind is the kind of a value which does not hold other values.
+type PrimitiveKind string
+
+const (
+	String  PrimitiveKind = "string"
+	Number  PrimitiveKind = "number"
+	Boolean PrimitiveKind = "boolean"
+	// int64 and uint64, which may not fit into a float64
+	Int64 PrimitiveKind = "int64"
+	// time.Time
+	Time PrimitiveKind = "time"
+	// time.Duration
+	Duration PrimitiveKind = "duration"
+	// uuid.UUID
+	UUID PrimitiveKind = "uuid"
+	// error
+	Error PrimitiveKind = "error"
+	// Value of any type (like interface{})
+	Any PrimitiveKind = "any"
+)
+
+// Type describes the type of a field or of a declaration.
+type Type struct {
+	Kind Kind
+	// Set if Kind == Primitive
+	Primitive PrimitiveKind
+	// Name of the alias, if Kind == Alias
+	Alias string
+	// Referenced declaration, if Kind == Reference
+	Decl *Decl
+	// Type of the elements, if Kind is Array, Map or Option
+	Elem *Type
+	// Type of the keys, if Kind == Map
+	Key *Type
+	// Fields of the object, if Kind == Object
+	Fields []*Field
+	// Possible types of the value, if Kind == Union
+	Variants []*Type
+	// Optional json name of the field, whose Literal value tells
+	// the variants of the Union apart
+	Discriminator string
+	// Constant value (string, float64, bool or nil), if Kind == Literal
+	Literal interface{}
+	// Types of the parameters and of the results, if Kind == Func.
+	// If Variadic is true, the last parameter is an Array of the
+	// variadic values.
+	Params, Results []*Type
+	Variadic        bool
+	// Go type from which the type was created (nil if unknown)
+	Go reflect.Type
+}
+
+// Field is a single field of a struct (or of an object).
+type Field struct {
+	// Name of the field in Go
+	Name string
+	// Name of the field in the marshalled json
+	JSONName string
+	// true if the field can be omitted from the json (",omitzero", or
+	// ",omitempty" for the kinds which encoding/json considers empty)
+	Optional bool
+	// true if the fields of the embedded struct are inlined into the
+	// parent (",inline", or embedded without a json name). Type is then
+	// an Object.
+	Inline bool
+	// true if the value is marshalled as a json string (",string")
+	Stringified bool
+	Type        *Type
+	// Struct tag of the field
+	Tag reflect.StructTag
+}
+
+// DeclKind is the kind of a declaration.
+type DeclKind int
+
+const (
+	// Struct with Decl.Fields
+	StructDecl DeclKind = iota
+	// Named alias of Decl.Type
+	AliasDecl
+)
+
+func (k DeclKind) String() string {
+	if k == AliasDecl {
+		return "alias"
+	}
+	return "struct"
+}
+
+// Decl is a named type which is emitted on its own.
+type Decl struct {
+	Name string
+	Kind DeclKind
+	// Fields of the struct, if Kind == StructDecl
+	Fields []*Field
+	// Aliased type, if Kind == AliasDecl
+	Type *Type
+	// Path of the Go package in which the type is declared
+	PkgPath string
+	// Go type from which the declaration was created (nil if unknown)
+	Go reflect.Type
+	// Declaration which holds the anonymous struct, from which the
+	// declaration was created (nil for the named types)
+	Parent *Decl
+	// Optional display labels of the values of an enum (an alias of
+	// a union of literals), in the order of the variants
+	Labels []string
+}
+
+// Graph holds the declarations which are converted together.
+type Graph struct {
+	Decls []*Decl
+	// Optional HTTP endpoints, which use the declarations
+	Endpoints []*Endpoint
+	// Optional RPC services, which use the declarations
+	Services []*Service
+}
+
+// Endpoint is a HTTP endpoint.
+type Endpoint struct {
+	// Name of the operation (like "getUser")
+	Name string
+	// HTTP method (like "GET")
+	Method string
+	// Path with the {name} or :name path parameters
+	Path string
+	// Optional types of the json request body, the json
+	// response and the query parameters (usually an object)
+	Request, Response, Query *Type
+}
+
+// Service is a named set of remote procedures (like a JSON-RPC service).
+type Service struct {
+	Name    string
+	Methods []*Method
+}
+
+// Method is a single procedure of a Service.
+type Method struct {
+	// Name of the method in Go (like "GetUser")
+	Name string
+	// Optional types of the params and of the result
+	Params, Result *Type
+}
+
+// Lookup returns the declaration which was created from the Go type.
+func (g *Graph) Lookup(t reflect.Type) *Decl {
+	for _, d := range g.Decls {
+		if d.Go == t && d.Kind == StructDecl {
+			return d
+		}
+	}
+	return nil
+}
+
+// Find returns the declaration with the name.
+func (g *Graph) Find(name string) *Decl {
+	for _, d := range g.Decls {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// ArrayOf returns an Array of the elements.
+func ArrayOf(elem *Type) *Type {
+	return &Type{Kind: Array, Elem: elem}
+}
+
+// RefTo returns a Reference to the declaration.
+func RefTo(d *Decl) *Type {
+	return &Type{Kind: Reference, Decl: d, Go: d.Go}
+}
+
+// Walk calls fn for the type and for all of the types which it holds
+// (elements, keys, fields, variants), without following references.
+// If fn returns false, the types held by the type are skipped.
+func Walk(t *Type, fn func(t *Type) bool) {
+	if t == nil || !fn(t) {
+		return
+	}
+	Walk(t.Key, fn)
+	Walk(t.Elem, fn)
+	for _, f := range t.Fields {
+		Walk(f.Type, fn)
+	}
+	for _, v := range t.Variants {
+		Walk(v, fn)
+	}
+	for _, p := range t.Params {
+		Walk(p, fn)
+	}
+	for _, res := range t.Results {
+		Walk(res, fn)
+	}
+}
+
+// Unwrap returns the type without the Option wrappers.
+func (t *Type) Unwrap() *Type {
+	for t != nil && t.Kind == Option {
+		t = t.Elem
+	}
+	return t
+}
@@ -0,0 +1,256 @@
+package ir_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/tompston/gut/ir"
+	"github.com/tompston/gut/types"
+)
+
+type Node struct {
+	Value    string  `json:"value"`
+	Children []*Node `json:"children,omitempty"`
+}
+
+type Tree struct {
+	Root Node `json:"root"`
+}
+
+func TestFromValues(t *testing.T) {
+	graph, err := ir.FromValues(types.StructWithReference{}, types.ReferenceStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(graph.Decls) != 2 {
+		t.Fatalf("expected 2 declarations, got %v", len(graph.Decls))
+	}
+
+	d := graph.Find("StructWithReference")
+	if d == nil || d.Kind != ir.StructDecl || d.PkgPath != "github.com/tompston/gut/types" {
+		t.Fatalf("unexpected declaration %+v", d)
+	}
+
+	ref := graph.Lookup(reflect.TypeOf(types.ReferenceStruct{}))
+	if ref == nil || ref.Name != "ReferenceStruct" {
+		t.Fatalf("ReferenceStruct is not declared")
+	}
+
+	tests := []struct {
+		name     string
+		jsonName string
+		optional bool
+		kind     ir.Kind
+	}{
+		{"MyString", "my_str", false, ir.Primitive},
+		{"MyInt", "MyInt", false, ir.Primitive},
+		{"Reference", "ref", false, ir.Reference},
+		// encoding/json never omits struct values
+		{"OptionalReference", "opt_ref", false, ir.Reference},
+	}
+
+	if len(d.Fields) != len(tests) {
+		t.Fatalf("expected %v fields, got %v", len(tests), len(d.Fields))
+	}
+
+	for i, tt := range tests {
+		f := d.Fields[i]
+		if f.Name != tt.name || f.JSONName != tt.jsonName || f.Optional != tt.optional || f.Type.Kind != tt.kind {
+			t.Errorf("field %v: got %+v with the kind %v", i, f, f.Type.Kind)
+		}
+	}
+
+	if d.Fields[2].Type.Decl != ref {
+		t.Errorf("the reference does not point to the ReferenceStruct declaration")
+	}
+}
+
+func TestOptionalFields(t *testing.T) {
+	graph, err := ir.FromValues(types.StructWithOmittedFields{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]bool{
+		"str":            true,
+		"num":            true,
+		"ptr":            true,
+		"slice":          true,
+		"map":            true,
+		"iface":          true,
+		"array":          false,
+		"empty_array":    true,
+		"ref":            false,
+		"time":           false,
+		"zero_num":       true,
+		"zero_array":     true,
+		"zero_ref":       true,
+		"zero_time":      true,
+		"zero_date":      true,
+		"required_ref":   false,
+		"required_slice": false,
+	}
+
+	fields := graph.Decls[0].Fields
+	if len(fields) != len(expected) {
+		t.Fatalf("expected %v fields, got %v", len(expected), len(fields))
+	}
+	for _, f := range fields {
+		if f.Optional != expected[f.JSONName] {
+			t.Errorf("%v: expected optional to be %v", f.JSONName, expected[f.JSONName])
+		}
+	}
+}
+
+func TestBuilderTypes(t *testing.T) {
+	b := ir.NewBuilder()
+
+	tests := []struct {
+		value interface{}
+		check func(typ *ir.Type) bool
+	}{
+		{"", func(typ *ir.Type) bool { return typ.Kind == ir.Primitive && typ.Primitive == ir.String }},
+		{int64(0), func(typ *ir.Type) bool { return typ.Primitive == ir.Int64 }},
+		{time.Time{}, func(typ *ir.Type) bool { return typ.Primitive == ir.Time }},
+		{time.Second, func(typ *ir.Type) bool { return typ.Primitive == ir.Duration }},
+		{[]int{}, func(typ *ir.Type) bool { return typ.Kind == ir.Array && typ.Elem.Primitive == ir.Number }},
+		// marshalled as base64 strings, unless the type is a json.Marshaler
+		{[]byte{}, func(typ *ir.Type) bool { return typ.Kind == ir.Primitive && typ.Primitive == ir.String }},
+		{[][]byte{}, func(typ *ir.Type) bool { return typ.Kind == ir.Array && typ.Elem.Primitive == ir.String }},
+		{json.RawMessage{}, func(typ *ir.Type) bool { return typ.Kind == ir.Array }},
+		{map[string]bool{}, func(typ *ir.Type) bool {
+			return typ.Kind == ir.Map && typ.Key.Primitive == ir.String && typ.Elem.Primitive == ir.Boolean
+		}},
+		{new(string), func(typ *ir.Type) bool { return typ.Kind == ir.Option && typ.Unwrap().Primitive == ir.String }},
+		{types.ReferenceStruct{}, func(typ *ir.Type) bool { return typ.Kind == ir.Object && len(typ.Fields) == 2 }},
+		{func(int, ...string) (bool, error) { return false, nil }, func(typ *ir.Type) bool {
+			return typ.Kind == ir.Func && typ.Variadic && len(typ.Params) == 2 && typ.Params[1].Kind == ir.Array &&
+				len(typ.Results) == 2 && typ.Results[1].Primitive == ir.Error
+		}},
+	}
+
+	for _, tt := range tests {
+		typ := b.Type(reflect.TypeOf(tt.value))
+		if !tt.check(typ) {
+			t.Errorf("unexpected conversion of %T: %+v", tt.value, typ)
+		}
+	}
+}
+
+func TestRecursiveStructsAreDeclared(t *testing.T) {
+	graph, err := ir.FromValues(Tree{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := graph.Find("Node")
+	if node == nil {
+		t.Fatalf("the recursive struct was not declared")
+	}
+
+	children := node.Fields[1].Type
+	if children.Kind != ir.Array || children.Elem.Unwrap().Decl != node {
+		t.Errorf("the children do not reference the Node declaration")
+	}
+}
+
+func TestAnonymousStructs(t *testing.T) {
+	b := ir.NewBuilder()
+	b.Anonymous = func(parent *ir.Decl, field reflect.StructField) (string, bool) {
+		return parent.Name + field.Name, field.Name != "Items"
+	}
+	order := b.Declare(reflect.TypeOf(types.Order{}), "Order")
+
+	graph, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	customer := graph.Find("OrderCustomer")
+	if customer == nil || customer.Parent != order || customer.PkgPath != order.PkgPath {
+		t.Fatalf("expected the OrderCustomer declaration, got %+v", customer)
+	}
+	if address := graph.Find("OrderCustomerAddress"); address == nil || address.Parent != customer {
+		t.Errorf("expected the nested OrderCustomerAddress declaration, got %+v", address)
+	}
+	// the structs with the same shape reference the same declaration
+	if billing := order.Fields[3].Type.Unwrap(); billing.Kind != ir.Reference || billing.Decl != customer {
+		t.Errorf("expected billing to reference OrderCustomer, got %+v", billing)
+	}
+	if items := order.Fields[2].Type; items.Elem.Kind != ir.Object {
+		t.Errorf("expected the items to be inlined, got %+v", items.Elem)
+	}
+}
+
+func TestNamedTypes(t *testing.T) {
+	b := ir.NewBuilder()
+	b.Named = func(t reflect.Type) (string, bool) {
+		return t.Name(), t.Name() != "ProjectID"
+	}
+	membership := b.Declare(reflect.TypeOf(types.Membership{}), "Membership")
+
+	graph, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := graph.Find("UserID")
+	if user == nil || user.Kind != ir.AliasDecl || user.Type.Primitive != ir.UUID {
+		t.Fatalf("expected UserID to be declared as an alias of a UUID, got %+v", user)
+	}
+	if org := graph.Find("OrgID"); org == nil || org.Type.Primitive != ir.Int64 {
+		t.Errorf("expected OrgID to be declared as an alias of an int64, got %+v", org)
+	}
+	if owners := membership.Fields[3].Type; owners.Elem.Kind != ir.Reference || owners.Elem.Decl != user {
+		t.Errorf("expected the owners to reference UserID, got %+v", owners.Elem)
+	}
+	if project := membership.Fields[2].Type.Unwrap(); project.Kind != ir.Primitive || project.Primitive != ir.String {
+		t.Errorf("expected the project to be converted in place, got %+v", project)
+	}
+
+	checksum := ir.NewBuilder().DeclareNamed(reflect.TypeOf(types.Checksum{}), "Checksum")
+	if checksum.Type.Kind != ir.Array || checksum.Type.Elem.Primitive != ir.Number {
+		t.Errorf("expected Checksum (without MarshalText) to be an array of numbers, got %+v", checksum.Type)
+	}
+}
+
+func TestEnums(t *testing.T) {
+	b := ir.NewBuilder()
+	ticket := b.Declare(reflect.TypeOf(types.Ticket{}), "Ticket")
+	status := b.DeclareEnum(reflect.TypeOf(types.Status("")), "Status", []interface{}{"active", "disabled"}, []string{"Active", "Disabled"})
+
+	if _, err := b.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if variants := status.Type.Variants; status.Type.Kind != ir.Union || len(variants) != 2 || variants[1].Literal != "disabled" {
+		t.Errorf("expected a union of the literal values, got %+v", status.Type)
+	}
+	if len(status.Labels) != 2 || status.Labels[0] != "Active" {
+		t.Errorf("unexpected labels %v", status.Labels)
+	}
+	if field := ticket.Fields[0].Type; field.Kind != ir.Reference || field.Decl != status {
+		t.Errorf("expected the status to reference the enum, got %+v", field)
+	}
+}
+
+func TestUnsupportedTypes(t *testing.T) {
+	for _, value := range []interface{}{types.StructWithChannel{}, struct{ P unsafe.Pointer }{}} {
+		if _, err := ir.FromValues(value); err == nil {
+			t.Errorf("expected an error for %T", value)
+		}
+	}
+}
+
+func TestDeclareOnlyStructs(t *testing.T) {
+	b := ir.NewBuilder()
+	b.Declare(reflect.TypeOf(""), "NotAStruct")
+
+	if _, err := b.Build(); err == nil {
+		t.Errorf("expected an error")
+	}
+}
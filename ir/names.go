@@ -0,0 +1,112 @@
+package ir
+
+import (
+	"reflect"
+	"strings"
+)
+
+// TypeName returns the name of the Go type, which can be used as an
+// identifier. reflect returns the names of the instantiated generics
+// together with their type arguments (e.g. "Page[github.com/x/y.User]"),
+// so the type arguments are appended to the name of the generic type
+// instead (Page[User] -> PageOfUser, Pair[string, []int] ->
+// PairOfStringAndIntArray).
+func TypeName(t reflect.Type) string {
+	return mangleName(t.Name())
+}
+
+// mangleName converts the name of a (generic) type into an identifier
+func mangleName(name string) string {
+	open := strings.IndexByte(name, '[')
+	if open < 0 || !strings.HasSuffix(name, "]") {
+		return name
+	}
+
+	args := splitTypeArgs(name[open+1 : len(name)-1])
+	mangled := make([]string, 0, len(args))
+	for _, arg := range args {
+		mangled = append(mangled, mangleTypeArg(arg))
+	}
+	return name[:open] + "Of" + strings.Join(mangled, "And")
+}
+
+// mangleTypeArg converts the Go type expression of a type argument
+// (like "map[string]interface {}") into a part of an identifier.
+func mangleTypeArg(arg string) string {
+	arg = strings.TrimSpace(arg)
+
+	switch {
+	case strings.HasPrefix(arg, "*"):
+		return mangleTypeArg(arg[1:])
+	case strings.HasPrefix(arg, "[]"):
+		return mangleTypeArg(arg[2:]) + "Array"
+	case strings.HasPrefix(arg, "["):
+		// fixed size arrays
+		return mangleTypeArg(arg[strings.IndexByte(arg, ']')+1:]) + "Array"
+	case strings.HasPrefix(arg, "map["):
+		end := matchingBracket(arg, len("map"))
+		return "MapOf" + mangleTypeArg(arg[len("map["):end]) + "To" + mangleTypeArg(arg[end+1:])
+	case arg == "interface {}" || arg == "any":
+		return "Any"
+	case strings.HasPrefix(arg, "interface"):
+		return "Interface"
+	case strings.HasPrefix(arg, "struct"):
+		return "Struct"
+	case strings.HasPrefix(arg, "func"):
+		return "Func"
+	case strings.HasPrefix(arg, "chan") || strings.HasPrefix(arg, "<-chan"):
+		return "Chan"
+	}
+
+	// named types are qualified with the path of their package
+	// (which may also hold dots, like github.com/x/y.User)
+	name := arg
+	if open := strings.IndexByte(name, '['); open >= 0 {
+		name = name[:open]
+	}
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		arg = arg[dot+1:]
+	}
+
+	arg = mangleName(arg)
+	return strings.ToUpper(arg[:1]) + arg[1:]
+}
+
+// splitTypeArgs splits the type arguments on the commas,
+// which are not nested in brackets, braces or parentheses.
+func splitTypeArgs(args string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range args {
+		switch c {
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, args[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, args[start:])
+}
+
+// matchingBracket returns the index of the bracket which
+// closes the bracket at the index open.
+func matchingBracket(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s) - 1
+}
@@ -0,0 +1,33 @@
+package ir_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tompston/gut/ir"
+	"github.com/tompston/gut/types"
+)
+
+func TestTypeName(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{types.SimpleStruct{}, "SimpleStruct"},
+		{types.StructWithGeneric[string]{}, "StructWithGenericOfString"},
+		{types.StructWithGeneric[map[string]interface{}]{}, "StructWithGenericOfMapOfStringToAny"},
+		{types.StructWithGeneric[[]*int64]{}, "StructWithGenericOfInt64Array"},
+		{types.StructWithGeneric[[3]bool]{}, "StructWithGenericOfBoolArray"},
+		{types.Page[types.SimpleStruct]{}, "PageOfSimpleStruct"},
+		{types.Page[types.Pair[string, []int]]{}, "PageOfPairOfStringAndIntArray"},
+		{types.Pair[int, map[string][]types.SimpleStruct]{}, "PairOfIntAndMapOfStringToSimpleStructArray"},
+		{types.Page[func(int) error]{}, "PageOfFunc"},
+		{types.Pair[string, struct{ A, B int }]{}, "PairOfStringAndStruct"},
+	}
+
+	for _, tt := range tests {
+		if got := ir.TypeName(reflect.TypeOf(tt.value)); got != tt.expected {
+			t.Errorf("TypeName(%T) = %v, expected %v", tt.value, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package gut
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	r "reflect"
+	"strings"
+
+	"github.com/tompston/gut/ir"
+)
+
+// jsonSchemaEmitter emits a JSON Schema (draft 2020-12) document,
+// in which every declaration is located under "$defs".
+type jsonSchemaEmitter struct {
+	s Settings
+	// prefix of the references to the declarations
+	refPrefix string
+	// number of the emitted declarations
+	count int
+}
+
+func newJSONSchemaEmitter(s Settings) Emitter {
+	return &jsonSchemaEmitter{s: s, refPrefix: "#/$defs/"}
+}
+
+func (e *jsonSchemaEmitter) Header(*ir.Graph) string {
+	return "{\n  \"$schema\": \"https://json-schema.org/draft/2020-12/schema\",\n  \"$defs\": {\n"
+}
+
+func (e *jsonSchemaEmitter) Footer(*ir.Graph) string {
+	return "\n  }\n}\n"
+}
+
+func (e *jsonSchemaEmitter) EmitDecl(d *ir.Decl, fields []string) string {
+	schema := e.declSchema(d, fields)
+
+	var buffer bytes.Buffer
+	if err := json.Indent(&buffer, []byte(schema), "    ", "  "); err != nil {
+		panic(fmt.Sprintf("Invalid json schema of %v! %v", d.Name, err))
+	}
+
+	sep := ""
+	if e.count > 0 {
+		sep = ",\n"
+	}
+	e.count++
+
+	return fmt.Sprintf("%s    %s: %s", sep, quote(d.Name), buffer.String())
+}
+
+// declSchema returns the compact schema of the declaration
+func (e *jsonSchemaEmitter) declSchema(d *ir.Decl, fields []string) string {
+	if d.Kind == ir.AliasDecl {
+		return e.schema(d.Type, false)
+	}
+	return objectSchema(fields, requiredFields(d.Fields))
+}
+
+// EmitField returns the compact "name": schema property of the field.
+// The properties of the inlined structs are returned together.
+func (e *jsonSchemaEmitter) EmitField(d *ir.Decl, f *ir.Field) string {
+	if f.Inline {
+		properties := make([]string, 0, len(f.Type.Fields))
+		for _, inlined := range f.Type.Fields {
+			properties = append(properties, e.EmitField(d, inlined))
+		}
+		return strings.Join(properties, ",")
+	}
+	return fmt.Sprintf("%s:%s", quote(f.JSONName), e.schema(f.Type, f.Stringified))
+}
+
+// schema returns the compact schema of the type. If the value is
+// stringified (",string" json option), scalars are strings.
+func (e *jsonSchemaEmitter) schema(typ *ir.Type, stringified bool) string {
+	switch typ.Kind {
+
+	case ir.Primitive:
+		if stringified && typ.Primitive != ir.Time && typ.Primitive != ir.UUID && typ.Primitive != ir.Any {
+			return `{"type":"string"}`
+		}
+
+		switch typ.Primitive {
+		case ir.String:
+			if ir.IsBytes(typ.Go) {
+				// base64 encoded bytes
+				return `{"type":"string","format":"byte"}`
+			}
+			return `{"type":"string"}`
+		case ir.Boolean:
+			return `{"type":"boolean"}`
+		case ir.Number:
+			if typ.Go != nil && typ.Go.Kind() != r.Float32 && typ.Go.Kind() != r.Float64 {
+				return `{"type":"integer"}`
+			}
+			return `{"type":"number"}`
+		case ir.Int64:
+			if int64Mode(e.s) == Int64String {
+				return `{"type":"string"}`
+			}
+			return `{"type":"integer"}`
+		case ir.Time:
+			return `{"type":"string","format":"date-time"}`
+		case ir.Duration:
+			switch durationFormat(e.s) {
+			case DurationSeconds:
+				return `{"type":"number"}`
+			case DurationISO8601:
+				return `{"type":"string","format":"duration"}`
+			case DurationString:
+				return `{"type":"string"}`
+			default:
+				return `{"type":"integer"}`
+			}
+		case ir.UUID:
+			return `{"type":"string","format":"uuid"}`
+		default:
+			return `{}`
+		}
+
+	case ir.Reference:
+		return fmt.Sprintf(`{"$ref":%s}`, quote(e.refPrefix+typ.Decl.Name))
+
+	case ir.Object:
+		properties := make([]string, 0, len(typ.Fields))
+		for _, f := range typ.Fields {
+			properties = append(properties, e.EmitField(nil, f))
+		}
+		return objectSchema(properties, requiredFields(typ.Fields))
+
+	case ir.Array:
+		return fmt.Sprintf(`{"type":"array","items":%s}`, e.schema(typ.Elem, false))
+
+	case ir.Map:
+		return fmt.Sprintf(`{"type":"object","additionalProperties":%s}`, e.schema(typ.Elem, false))
+
+	case ir.Option:
+		return fmt.Sprintf(`{"anyOf":[%s,{"type":"null"}]}`, e.schema(typ.Elem, stringified))
+
+	case ir.Union:
+		variants := make([]string, 0, len(typ.Variants))
+		for _, v := range typ.Variants {
+			variants = append(variants, e.schema(v, false))
+		}
+		return fmt.Sprintf(`{"anyOf":[%s]}`, strings.Join(variants, ","))
+
+	case ir.Literal:
+		literal, _ := json.Marshal(typ.Literal)
+		return fmt.Sprintf(`{"const":%s}`, literal)
+
+	default:
+		// custom aliases do not have a known schema
+		return `{}`
+	}
+}
+
+func objectSchema(properties []string, required []string) string {
+	sb := strings.Builder{}
+	sb.WriteString(`{"type":"object","properties":{`)
+	sb.WriteString(strings.Join(nonEmpty(properties), ","))
+	sb.WriteString("}")
+	if len(required) > 0 {
+		quoted := make([]string, 0, len(required))
+		for _, name := range required {
+			quoted = append(quoted, quote(name))
+		}
+		sb.WriteString(fmt.Sprintf(`,"required":[%s]`, strings.Join(quoted, ",")))
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// requiredFields returns the json names of the fields which are not optional
+func requiredFields(fields []*ir.Field) []string {
+	var required []string
+	for _, f := range fields {
+		if f.Inline {
+			required = append(required, requiredFields(f.Type.Fields)...)
+		} else if !f.Optional {
+			required = append(required, f.JSONName)
+		}
+	}
+	return required
+}
+
+func nonEmpty(values []string) []string {
+	var filtered []string
+	for _, v := range values {
+		if v != "" {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
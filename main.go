@@ -2,18 +2,20 @@ package gut
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
-	"os"
 	r "reflect"
 	"regexp"
 	"strings"
-	"time"
+
+	"github.com/tompston/gut/ir"
 )
 
 // Settings struct is passed to the Generate function,
 // in order to specify what types you want to use for the emitted typescript
 // interface fields, when the structs include the following types:
 //   - time.Time
+//   - time.Duration
 //   - uuid.UUID
 //   - Int64 / Uint64
 type Settings struct {
@@ -27,10 +29,44 @@ type Settings struct {
 	// type for the emitted uuid.UUID types. Default should
 	// be string, but can be changed if needed
 	UuidType string
-	// Not sure how js / ts handles int64 or uint64 types, so
-	// Specify what type you want to use. Can be either
-	// "number" or "BigInt"
+	// Defines how the int64 / uint64 values are represented in
+	// typescript. (Default = Int64Number, because that is what
+	// JSON.parse returns)
+	Int64Mode Int64Mode
+	// Optional type for the emitted int64 / uint64 values. By
+	// default, the type is based on the Int64Mode.
 	BigIntType string
+	// Format in which the time.Duration values are marshalled
+	// to json. (Default = DurationNanoseconds, which is how
+	// encoding/json marshals time.Duration)
+	DurationFormat DurationFormat
+	// Optional type for the emitted time.Duration values. By
+	// default, the type is based on the DurationFormat.
+	DurationType string
+	// Optional type for the emitted error values (like the
+	// parameters of the callbacks). (Default = "Error")
+	ErrorType string
+	// if set to true, runtime helpers (like parseDuration) are
+	// declared in the header for the used aliases. (Default = false)
+	Helpers bool
+	// Optional map of alias names to their typescript types, which
+	// overrides the types of the aliases registered with RegisterAlias.
+	Aliases map[string]string
+	// Optional prefix which is added to the names of the aliases
+	// in the header (e.g. "Api" -> ApiDateType), so that they do not
+	// collide with the aliases of other generated files.
+	AliasPrefix string
+	// Optional name of the namespace in which the aliases are
+	// declared (e.g. "gut" -> gut.DateType).
+	AliasNamespace string
+	// Optional title and version of the API, which are
+	// used by the openapi emitters. (Default = "API", "0.0.0")
+	APITitle   string
+	APIVersion string
+	// Optional logger which reports the status of the generated
+	// files. If nil, the status is printed to stdout. Use
+	// DiscardLogger to silence the output.
+	Logger Logger
 }
 
 // Type is an optional struct that can be passed to the Convert function, which modifies the generated typescript interfaces
@@ -41,139 +77,573 @@ type Type struct {
 	IsArray bool
 	// optional name for the type that holds the array of interfaces (default = Name + "Array")
 	ArrayTypeName string
+	// if set to true, a revive<Name> function which converts the int64 / uint64
+	// values of the parsed json to bigint is emitted after the interface.
+	// Meant to be used together with Int64Mode = Int64BigInt. (Default = false)
+	Reviver bool
+	// Optional name of the typescript module (file without the .ts extension) in
+	// which the interface is declared, when it is added to a Registry.
+	// (Default = name of the Go package)
+	Module string
+	// if set to true, the inlined structs (",inline") are declared as separate
+	// interfaces, which the interface extends, instead of copying their fields.
+	// If the interface declares fields with the same names, an intersection
+	// type is emitted instead (Omit<Base, "field"> & {...}). (Default = false)
+	Extends bool
+	// if set to true, an is<Name>(x: unknown): x is <Name> type guard, which
+	// structurally checks the value, is emitted after the interface. (Default = false)
+	Guard bool
+	// if set to true, decode<Name>(json: unknown) and encode<Name>(value) functions
+	// are emitted after the interface, which convert the time.Time and int64 / uint64
+	// values between json and the DateType / BigIntType aliases. (Default = false)
+	Codec bool
+	// if set to true, the anonymous structs of the fields are declared as
+	// separate interfaces (which inherit these settings), instead of being
+	// inlined. Structs with the same shape are declared once. (Default = false)
+	DeclareAnonymous bool
+	// Optional func which returns the name of an anonymous struct. The field
+	// is the Go name of the field which holds the struct, or empty for the
+	// elements of an array. (Default = parent + field, e.g. "UserAddress",
+	// or parent + "Item" for the elements, e.g. "EmployeesItem")
+	AnonymousName func(parent string, field string) string
+	// if set to true, a <Name>Create interface is emitted after the interface,
+	// which does not hold the fields that are set by the server
+	// (`gut:"server"`). (Default = false)
+	Create bool
+	// if set to true, a <Name>Update interface is emitted after the interface,
+	// in which the fields that can be changed (not `gut:"server"` or
+	// `gut:"readonly"`) are optional and the nested structs are wrapped in
+	// DeepPartial. (Default = false)
+	Update bool
+	// Optional projections of the interface. The keys are the names of the
+	// emitted types and the values are the json names of the fields, which
+	// are picked (export type UserSummary = Pick<User, "id" | "name">) or
+	// omitted (export type PublicUser = Omit<User, "password">).
+	Pick map[string][]string
+	Omit map[string][]string
+	// if set to true, the converted named type (like type UserID int64) is
+	// emitted as a branded type (UserID = BigIntType & { readonly __brand:
+	// "UserID" }), followed by the toUserID(value) function which creates it.
+	// Types which are held by the fields can be branded with Brand. (Default = false)
+	Brand bool
 }
 
-// toTS converts the passed down type to the corresponding typescript interface type.
-func toTS(typ r.Type, typeMap map[string]r.Type, inline ...bool) string {
+// tsEmitter converts the declarations of the ir.Graph
+// into typescript interfaces and types.
+type tsEmitter struct {
+	// graph of the converted declarations
+	graph *ir.Graph
+	// settings of the declarations which were created
+	// from the converted structs
+	settings map[*ir.Decl]Type
+	// declarations which were referenced during the conversion
+	referenced map[*ir.Decl]bool
+	// indentation of the properties which are currently converted
+	indent string
+	// if set, the read only fields of the declaration which is currently
+	// converted are emitted as readonly properties
+	readonly bool
+	// Optional namespaces of the declarations and the namespace which is
+	// currently emitted. The references to the declarations in other
+	// namespaces are qualified with their namespace.
+	namespaces map[*ir.Decl]string
+	namespace  string
+	// aliases and helpers which were used during the conversion, from
+	// which the header is created
+	used usage
+	// Optional prefix (and namespace) of the aliases from the Settings
+	qualifier string
+}
 
-	var isInline bool
-	if len(inline) > 0 && inline[0] {
-		isInline = true
+func newTSEmitter(graph *ir.Graph, settings map[*ir.Decl]Type) *tsEmitter {
+	return &tsEmitter{
+		graph:      graph,
+		settings:   settings,
+		referenced: make(map[*ir.Decl]bool),
+		used:       newUsage(),
 	}
+}
 
-	switch typ.Kind() {
+// alias marks the alias as used and returns its qualified name
+func (e *tsEmitter) alias(name string) string {
+	e.used.aliases[name] = true
+	return e.qualifier + name
+}
 
-	case r.Struct:
-		sb := strings.Builder{}
+// helper marks the helper of the header as used and returns its name
+func (e *tsEmitter) helper(name string) string {
+	e.used.helpers[name] = true
+	return name
+}
 
-		if !isInline {
-			sb.WriteString(" {\n")
-		}
+// toTS converts the passed down type to the corresponding typescript interface type.
+func (e *tsEmitter) toTS(typ *ir.Type) string {
+	switch typ.Kind {
 
-		if typ == r.TypeOf(time.Time{}) {
-			return "DateType"
+	case ir.Primitive:
+		switch typ.Primitive {
+		case ir.String:
+			return "string"
+		case ir.Boolean:
+			return "boolean"
+		case ir.Number:
+			return "number"
+		case ir.Int64:
+			return e.alias("BigIntType")
+		case ir.Time:
+			return e.alias("DateType")
+		case ir.Duration:
+			return e.alias("DurationType")
+		case ir.UUID:
+			return e.alias("UuidType")
+		case ir.Error:
+			return e.alias("ErrorType")
+		default:
+			return "any"
 		}
 
-		for i := 0; i < typ.NumField(); i++ {
-			field := typ.Field(i)
-			if field.PkgPath != "" { // Skip unexported fields
-				continue
-			}
+	case ir.Alias:
+		return e.alias(typ.Alias)
 
-			if hasInlineJsonTag(field) {
-				sb.WriteString(fmt.Sprintf("%v\n", toTS(field.Type, typeMap, true)))
-			} else {
-				sb.WriteString(fmt.Sprintf("%v: %v\n", typescriptFieldname(field), toTS(field.Type, typeMap)))
-			}
+	case ir.Reference:
+		e.referenced[typ.Decl] = true
+		return e.qualified(typ.Decl, typ.Decl.Name)
+
+	case ir.Object:
+		indent := e.indent
+		defer func() { e.indent = indent }()
+		return fmt.Sprintf("{\n%s%s}", e.fields(typ.Fields, indent+"  "), indent)
+
+	case ir.Array:
+		if typ.Elem.Kind == ir.Union || typ.Elem.Unwrap().Kind == ir.Func {
+			return fmt.Sprintf("(%v)[]", e.toTS(typ.Elem))
 		}
+		return fmt.Sprintf("%v[]", e.toTS(typ.Elem))
 
-		if !isInline {
-			sb.WriteString("}")
+	case ir.Map:
+		return fmt.Sprintf("{[key: %v]: %v}", e.toTS(typ.Key), e.toTS(typ.Elem))
+
+	case ir.Option:
+		return e.toTS(typ.Elem)
+
+	case ir.Union:
+		variants := make([]string, 0, len(typ.Variants))
+		for _, v := range typ.Variants {
+			variants = append(variants, e.toTS(v))
 		}
-		return sb.String()
+		return strings.Join(variants, " | ")
 
-	case r.Slice:
-		return fmt.Sprintf("%v[]", toTS(typ.Elem(), typeMap))
+	case ir.Literal:
+		literal, _ := json.Marshal(typ.Literal)
+		return string(literal)
 
-	/*
-		This is commented out because uuid.UUID is converted
-		into a number[] type in the typescript interfaces,
-		not the expected UuidType.
-	*/
-	// case r.Array:
-	// 	return fmt.Sprintf("%v[]", toTS(typ.Elem(), typeMap))
+	case ir.Func:
+		return e.funcTS(typ)
 
-	case r.Map:
-		return fmt.Sprintf("{[key: %v]: %v}", toTS(typ.Key(), typeMap), toTS(typ.Elem(), typeMap))
+	default:
+		return "any"
+	}
+}
 
-	case r.Ptr:
-		return toTS(typ.Elem(), typeMap)
+// qualified returns the name (of the declaration, or of its function),
+// qualified with the namespace of the declaration, if it is declared in
+// another namespace.
+func (e *tsEmitter) qualified(d *ir.Decl, name string) string {
+	if ns := e.namespaces[d]; ns != "" && ns != e.namespace {
+		return ns + "." + name
+	}
+	return name
+}
 
+// funcTS converts the func into a typescript function type. The error
+// result is dropped (the errors are thrown in typescript) and multiple
+// results are returned as a tuple.
+func (e *tsEmitter) funcTS(typ *ir.Type) string {
+	params := make([]string, 0, len(typ.Params))
+	for i, p := range typ.Params {
+		if typ.Variadic && i == len(typ.Params)-1 {
+			params = append(params, fmt.Sprintf("...arg%d: %s", i, e.toTS(p)))
+		} else {
+			params = append(params, fmt.Sprintf("arg%d: %s", i, e.toTS(p)))
+		}
+	}
+
+	results := typ.Results
+	if n := len(results); n > 0 && results[n-1].Kind == ir.Primitive && results[n-1].Primitive == ir.Error {
+		results = results[:n-1]
+	}
+
+	var result string
+	switch len(results) {
+	case 0:
+		result = "void"
+	case 1:
+		result = e.toTS(results[0])
 	default:
-		if typ.Name() != "" {
-			if _, ok := typeMap[typ.Name()]; !ok {
-				typeMap[typ.Name()] = typ
+		converted := make([]string, 0, len(results))
+		for _, res := range results {
+			converted = append(converted, e.toTS(res))
+		}
+		result = fmt.Sprintf("[%s]", strings.Join(converted, ", "))
+	}
+
+	return fmt.Sprintf("(%s) => %s", strings.Join(params, ", "), result)
+}
+
+// fields converts the fields of a struct into typescript properties.
+// The fields of the inlined structs are added to the parent.
+func (e *tsEmitter) fields(fields []*ir.Field, indent string) string {
+	sb := strings.Builder{}
+	for _, field := range fields {
+		if field.Inline {
+			sb.WriteString(e.fields(field.Type.Fields, indent))
+		} else {
+			e.indent = indent
+			modifier := ""
+			if e.readonly && isReadonly(field) {
+				modifier = "readonly "
 			}
+			sb.WriteString(fmt.Sprintf("%s%s%s: %s\n", indent, modifier, typescriptFieldname(field), e.fieldTS(field)))
 		}
+	}
+	return sb.String()
+}
 
-		switch typ.Kind() {
-		case r.String:
+// fieldTS converts the type of the struct field, taking into account
+// the ",string" json option, which marshals scalars as strings.
+func (e *tsEmitter) fieldTS(field *ir.Field) string {
+	if typ := field.Type.Unwrap(); field.Stringified && typ.Kind == ir.Primitive {
+		switch typ.Primitive {
+		case ir.Int64:
+			return e.alias("BigIntStringType")
+		case ir.String, ir.Boolean, ir.Number, ir.Duration:
 			return "string"
-		case r.Bool:
-			return "boolean"
-		case
-			r.Float32, r.Float64,
-			r.Int, r.Int8, r.Int16, r.Int32,
-			r.Uint, r.Uint8, r.Uint16, r.Uint32:
-			return "number"
-		case r.Int64, r.Uint64:
-			return "BigIntType"
-		default:
-			if refType, ok := typeMap[typ.Name()]; ok {
-				if refType.Name() == "UUID" {
-					return "UuidType"
-				}
-				return refType.Name()
-			}
-			return "any"
 		}
 	}
+	return e.toTS(field.Type)
 }
 
-func parseStruct(structType r.Type, structTypes map[string]r.Type, typeSettings ...Type) string {
-	var buffer bytes.Buffer
+// parseStruct converts the declaration into a typescript interface (or
+// a type alias), followed by the optional helpers of the declaration.
+func (e *tsEmitter) parseStruct(decl *ir.Decl) string {
+	return emitDecl(e, decl)
+}
 
-	typeName := structType.Name()
+// EmitField converts the field into a typescript property. The
+// properties of the inlined structs are returned together.
+func (e *tsEmitter) EmitField(decl *ir.Decl, field *ir.Field) string {
+	e.readonly = marksReadonly(e.settings[decl])
+	return e.fields([]*ir.Field{field}, "  ")
+}
 
-	if len(typeSettings) == 1 {
-		gutType := typeSettings[0]
-		if gutType.Name == "" {
-			gutType.Name = typeName
+// EmitDecl converts the declaration into a typescript interface (or a
+// type alias), followed by the optional helpers of the declaration.
+func (e *tsEmitter) EmitDecl(decl *ir.Decl, fields []string) string {
+	var buffer bytes.Buffer
+
+	if decl.Kind == ir.AliasDecl {
+		if e.isBrand(decl) {
+			buffer.WriteString(e.brandedType(decl))
+		} else {
+			buffer.WriteString(fmt.Sprintf("export type %s = %s \n\n", decl.Name, e.toTS(decl.Type)))
 		}
-		if !isValidTypeName(gutType.Name) {
-			panic(fmt.Sprintf("Invalid typescript interface name was provided! %v", gutType.Name))
+		if isEnum(decl) {
+			buffer.WriteString(e.enumConstants(decl))
 		}
+		if e.settings[decl].Guard {
+			buffer.WriteString(e.guardFunction(decl))
+		}
+		if e.settings[decl].Codec {
+			buffer.WriteString(e.codecFunctions(decl))
+		}
+		return buffer.String()
+	}
+
+	// Start of the type
+	if bases, own := e.bases(decl); len(bases) > 0 {
+		buffer.WriteString(e.extends(decl, bases, own))
+	} else {
+		buffer.WriteString(fmt.Sprintf("export interface %s {\n", decl.Name))
+		buffer.WriteString(strings.Join(fields, ""))
+		buffer.WriteString("}\n\n")
+	}
 
-		// set typeName to hold the custom value, if it was provided
-		typeName = gutType.Name
+	buffer.WriteString(e.derivedTypes(decl))
 
-		if gutType.IsArray {
-			array_type_name := gutType.ArrayTypeName
-			if array_type_name == "" {
-				array_type_name = fmt.Sprintf("%sArray", typeName)
+	if e.settings[decl].Reviver {
+		buffer.WriteString(e.reviverFunction(decl))
+	}
+
+	if e.settings[decl].Guard {
+		buffer.WriteString(e.guardFunction(decl))
+	}
+
+	if e.settings[decl].Codec {
+		buffer.WriteString(e.codecFunctions(decl))
+	}
+
+	return buffer.String()
+}
+
+// bases returns the declarations of the inlined structs, which the
+// interface extends (if Type.Extends is set), and the other fields.
+func (e *tsEmitter) bases(decl *ir.Decl) ([]*ir.Decl, []*ir.Field) {
+	if !e.settings[decl].Extends || e.graph == nil {
+		return nil, nil
+	}
+
+	var bases []*ir.Decl
+	var own []*ir.Field
+	for _, field := range decl.Fields {
+		if field.Inline && field.Type.Go != nil {
+			if base := e.graph.Lookup(field.Type.Go); base != nil {
+				bases = append(bases, base)
+				continue
 			}
-			buffer.WriteString(fmt.Sprintf("export type %s = %s[] \n\n", array_type_name, typeName))
 		}
+		own = append(own, field)
 	}
+	return bases, own
+}
 
-	// Start of the type
-	buffer.WriteString(fmt.Sprintf("export interface %s {\n", typeName))
-	for i := 0; i < structType.NumField(); i++ {
-		field := structType.Field(i)
-		if hasInlineJsonTag(field) {
-			buffer.WriteString(fmt.Sprintf("  %s\n", toTS(field.Type, structTypes, true)))
+// extends converts the declaration into an interface which extends the
+// bases, or into an intersection type, if the own fields of the interface
+// have the same names as the fields of the bases.
+func (e *tsEmitter) extends(decl *ir.Decl, bases []*ir.Decl, own []*ir.Field) string {
+	e.readonly = marksReadonly(e.settings[decl])
+
+	names := make(map[string]bool)
+	for _, name := range jsonNames(own) {
+		names[name] = true
+	}
+
+	conflict := false
+	types := make([]string, 0, len(bases))
+	for _, base := range bases {
+		e.referenced[base] = true
+
+		var omitted []string
+		for _, name := range jsonNames(base.Fields) {
+			if names[name] {
+				omitted = append(omitted, fmt.Sprintf("%q", name))
+			}
+		}
+
+		if len(omitted) > 0 {
+			conflict = true
+			types = append(types, fmt.Sprintf("Omit<%s, %s>", e.qualified(base, base.Name), strings.Join(omitted, " | ")))
 		} else {
-			buffer.WriteString(fmt.Sprintf("  %s: %s\n", typescriptFieldname(field), toTS(field.Type, structTypes)))
+			types = append(types, e.qualified(base, base.Name))
 		}
+	}
 
+	if conflict {
+		return fmt.Sprintf("export type %s = %s & {\n%s}\n\n", decl.Name, strings.Join(types, " & "), e.fields(own, "  "))
 	}
+	return fmt.Sprintf("export interface %s extends %s {\n%s}\n\n", decl.Name, strings.Join(types, ", "), e.fields(own, "  "))
+}
 
-	buffer.WriteString("}\n\n")
-	return buffer.String()
+// jsonNames returns the json names of the fields (including the inlined ones)
+func jsonNames(fields []*ir.Field) []string {
+	var names []string
+	for _, f := range fields {
+		if f.Inline {
+			names = append(names, jsonNames(f.Type.Fields)...)
+		} else {
+			names = append(names, f.JSONName)
+		}
+	}
+	return names
+}
+
+// The header and the imports are added to the typescript declarations
+// when the file is rendered, so the emitter does not have them.
+func (e *tsEmitter) Header(*ir.Graph) string { return "" }
+func (e *tsEmitter) Footer(*ir.Graph) string { return "" }
+
+// declare adds the declaration of the struct (and of the optional array
+// alias) to the builder, and returns them in the order of emission.
+func declare(b *ir.Builder, structType r.Type, gutType Type) []*ir.Decl {
+	decl := b.Declare(structType, gutType.Name)
+
+	if gutType.IsArray {
+		array_type_name := gutType.ArrayTypeName
+		if array_type_name == "" {
+			array_type_name = fmt.Sprintf("%sArray", gutType.Name)
+		}
+		array := b.DeclareAlias(array_type_name, ir.ArrayOf(ir.RefTo(decl)), structType)
+		return []*ir.Decl{array, decl}
+	}
+
+	return []*ir.Decl{decl}
 }
 
-func hasInlineJsonTag(field r.StructField) bool {
-	return strings.Contains(field.Tag.Get("json"), ",inline")
+// declareBases declares the inlined structs of the struct (which the
+// interface extends, if Type.Extends is set), if they are not declared yet.
+func declareBases(b *ir.Builder, structType r.Type) []*ir.Decl {
+	var bases []*ir.Decl
+	for i := 0; i < structType.NumField(); i++ {
+		f := structType.Field(i)
+		t := f.Type
+		if t.Kind() == r.Ptr {
+			t = t.Elem()
+		}
+		if f.PkgPath != "" || t.Kind() != r.Struct || t.Name() == "" || !isInlined(f) {
+			continue
+		}
+		if b.Decl(t) == nil {
+			bases = append(bases, b.Declare(t, ir.TypeName(t)))
+		}
+	}
+	return bases
+}
+
+// isInlined checks if the fields of the embedded struct are inlined into
+// the parent (",inline", or embedded without a json name, like encoding/json)
+func isInlined(f r.StructField) bool {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	return hasJSONOption(f.Tag, "inline") || f.Anonymous && name == ""
+}
+
+func hasJSONOption(tag r.StructTag, option string) bool {
+	for _, opt := range strings.Split(tag.Get("json"), ",")[1:] {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// newBuilder returns an ir.Builder which converts the
+// types registered with RegisterAlias to the aliases. The
+// anonymous structs are declared based on the settings of
+// the declarations which hold them.
+func newBuilder(settings map[*ir.Decl]Type) *ir.Builder {
+	b := ir.NewBuilder()
+	b.Alias = registeredAlias
+	b.Named = brandedName
+	b.Anonymous = func(parent *ir.Decl, field r.StructField) (string, bool) {
+		s := rootSettings(settings, parent)
+		if !s.DeclareAnonymous {
+			return "", false
+		}
+		return anonymousName(s, parent.Name, field.Name), true
+	}
+	return b
+}
+
+// anonymousName returns the name of the anonymous struct, which is held by
+// the field of the parent (or is the element of the parent array, if the
+// field is empty).
+func anonymousName(s Type, parent string, field string) string {
+	name := parent + field
+	if field == "" {
+		name = parent + "Item"
+	}
+	if s.AnonymousName != nil {
+		name = s.AnonymousName(parent, field)
+	}
+	if !isValidTypeName(name) {
+		panic(fmt.Sprintf("Invalid typescript name of an anonymous struct was provided! %v", name))
+	}
+	return name
+}
+
+// nameArrayItems names the anonymous structs of the array (if the name of
+// the interface was not provided), so that the array type keeps the name
+// of the Go type. (type Employees []struct{...} -> Employees = EmployeesItem[])
+func nameArrayItems(gutType Type, arrayType r.Type, named bool) Type {
+	if named || arrayType.Name() == "" || arrayElem(arrayType).Name() != "" || gutType.ArrayTypeName != "" {
+		return gutType
+	}
+	gutType.ArrayTypeName = ir.TypeName(arrayType)
+	gutType.Name = anonymousName(gutType, ir.TypeName(arrayType), "")
+	return gutType
+}
+
+// inheritedSettings returns the settings of the anonymous struct,
+// which are inherited from the declaration which holds it.
+func inheritedSettings(parent Type) Type {
+	parent.Name = ""
+	parent.IsArray = false
+	parent.ArrayTypeName = ""
+	parent.Extends = false
+	parent.Create = false
+	parent.Update = false
+	parent.Pick = nil
+	parent.Omit = nil
+	return parent
+}
+
+// rootSettings returns the settings of the declaration, or the inherited
+// settings of its parent, if the declaration is an anonymous struct.
+func rootSettings(settings map[*ir.Decl]Type, d *ir.Decl) Type {
+	if s, ok := settings[d]; ok || d.Parent == nil {
+		return s
+	}
+	return inheritedSettings(rootSettings(settings, d.Parent))
+}
+
+// build builds the graph and converts the
+// discriminator fields of the unions into literals.
+func build(b *ir.Builder) (*ir.Graph, error) {
+	graph, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	// the names of the implicit declarations (like recursive
+	// structs) are not validated when they are declared
+	for _, d := range graph.Decls {
+		if !isValidTypeName(d.Name) {
+			return nil, fmt.Errorf("gut: %q (%v) is not a valid typescript name", d.Name, d.Go)
+		}
+	}
+	if err := applyDiscriminators(graph); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// checkCollisions returns an error if different declarations (or the types
+// which are derived from them) have the same name (like the
+// instantiations of generics, whose names are mangled).
+func checkCollisions(decls []*ir.Decl, settings map[*ir.Decl]Type) error {
+	declared := make(map[string]*ir.Decl, len(decls))
+	for _, d := range decls {
+		if other, ok := declared[d.Name]; ok {
+			return fmt.Errorf("gut: %v is declared more than once (%v and %v)", d.Name, other.Go, d.Go)
+		}
+		declared[d.Name] = d
+	}
+
+	for _, d := range decls {
+		if err := checkDerived(d, settings[d]); err != nil {
+			return err
+		}
+		for _, name := range derivedNames(d, settings[d]) {
+			if other, ok := declared[name]; ok {
+				return fmt.Errorf("gut: %v (derived from %v) is declared more than once (%v)", name, d.Name, other.Go)
+			}
+			declared[name] = d
+		}
+	}
+	return nil
+}
+
+// emitOrder returns the declarations of the graph, starting with the
+// passed in declarations, followed by the ones which were added during
+// the build (like recursive structs).
+func emitOrder(graph *ir.Graph, decls []*ir.Decl) []*ir.Decl {
+	seen := make(map[*ir.Decl]bool, len(decls))
+	for _, d := range decls {
+		seen[d] = true
+	}
+	for _, d := range graph.Decls {
+		if !seen[d] {
+			decls = append(decls, d)
+		}
+	}
+	return decls
 }
 
 // Convert converts the passed in struct into a typescript interface
@@ -181,95 +651,189 @@ func hasInlineJsonTag(field r.StructField) bool {
 // param, which is used to optionally define the settings of the generated
 // typescript interface.
 //
+// Other named types (maps, slices, primitives, funcs) are converted into
+// a type alias, in which the named structs are referenced by name (and
+// declared after the alias).
+//
 // Example
 //
 //	ex1 := gut.Convert(MyStruct{})
 //	ex2 := gut.Convert(MyStruct{}, gut.Type{Name: "MyStructCustomName", IsArray : true})
+//	ex3 := gut.Convert(Index{}) // type Index map[string]User -> export type Index = {[key: string]: User}
 func Convert(i interface{}, typeSettings ...Type) string {
 
-	_type := make(map[string]r.Type)
 	_typeof := r.TypeOf(i)
 
+	var gutType Type
+	if len(typeSettings) == 1 {
+		gutType = typeSettings[0]
+	}
+
+	if u, ok := i.(UnionType); ok {
+		return convertUnion(u, gutType)
+	}
+	if en, ok := i.(EnumType); ok {
+		return convertEnum(en, gutType)
+	}
+
+	if _typeof == nil {
+		panic("Only structs or named types can be converted! <nil>")
+	}
+	if isTypeAlias(_typeof) {
+		return convertAlias(_typeof, gutType)
+	}
+
 	if structIsArray(i) {
 		// if the input struct is an array and the settings are present,
 		// create a typescript interface with the settings if the
 		// Interface.Name is present
 		if len(typeSettings) == 1 {
-			settings := typeSettings[0]
-			settings.IsArray = true
-			if settings.Name == "" {
+			if gutType.Name == "" {
 				panic("The name for the array of structs cannot be empty!")
 			}
-			return parseStruct(_typeof.Elem(), _type, settings)
+		} else {
+			// else, if the interface is an array, but the settings are not present, use the name of the array.
+			gutType.Name = ir.TypeName(_typeof)
 		}
-		// else, if the interface is an array, but the settings are not present, set the IsArray setting to true.
-		return parseStruct(_typeof.Elem(), _type, Type{IsArray: true, Name: _typeof.Name()})
+		gutType = nameArrayItems(gutType, _typeof, len(typeSettings) == 1)
+		gutType.IsArray = true
+		_typeof = arrayElem(_typeof)
+	} else if gutType.Name == "" {
+		gutType.Name = ir.TypeName(_typeof)
 	}
-	// if the input struct is not an array and the settings are present
-	if len(typeSettings) == 1 {
-		return parseStruct(_typeof, _type, typeSettings[0])
+
+	if !isValidTypeName(gutType.Name) {
+		panic(fmt.Sprintf("Invalid typescript interface name was provided! %v", gutType.Name))
 	}
-	// If the settings array is not present and the struct is not an array
-	return parseStruct(_typeof, _type)
-}
 
-/* convert the field name into a valid value, based on the json tags */
-func typescriptFieldname(field r.StructField) string {
-	json_tag := field.Tag.Get("json")
-	tag_name := strings.Split(json_tag, ",")[0]
-	if json_tag == "" {
-		return field.Name
-	} else if strings.Contains(json_tag, ",omitempty") {
-		return fmt.Sprintf("%v? ", tag_name)
-	} else {
-		return fmt.Sprintf("%v ", tag_name)
+	settings := map[*ir.Decl]Type{}
+	b := newBuilder(settings)
+	decls := declare(b, _typeof, gutType)
+	if gutType.Extends {
+		declareBases(b, _typeof)
 	}
+	for _, d := range decls {
+		settings[d] = gutType
+	}
+
+	return emitDecls(b, decls, settings)
 }
 
-// Default settings for the generated typescript file. Be free to create a custom Settings struct if needed.
-var defaultSettings = Settings{
-	DateType:   "Date",
-	UuidType:   "string",
-	BigIntType: "BigInt",
+// convertUnion converts the union and its variants
+func convertUnion(u UnionType, gutType Type) string {
+	if gutType.Name == "" {
+		gutType.Name = ir.TypeName(u.typ)
+	}
+	if !isValidTypeName(gutType.Name) {
+		panic(fmt.Sprintf("Invalid typescript union name was provided! %v", gutType.Name))
+	}
+
+	settings := map[*ir.Decl]Type{}
+	b := newBuilder(settings)
+	return emitDecls(b, declareUnion(b, u, gutType.Name), settings)
 }
 
-// Generate function creates a file  and saves the passed in content to it + appends
-// the settings types at the start. If the 3rd optional param is present, it will be used
-// to override the default settings.
-func Generate(filename string, content string, settings ...Settings) error {
-	// if the settings are not present, use the default settings, else use the provided settings
-	var s Settings
-	if len(settings) == 1 {
-		s = settings[0]
+// convertAlias converts the named type into a type alias. The named
+// structs which it holds are declared after the alias.
+func convertAlias(t r.Type, gutType Type) string {
+	if gutType.Name == "" {
+		gutType.Name = ir.TypeName(t)
+	}
+	if !isValidTypeName(gutType.Name) {
+		panic(fmt.Sprintf("Invalid typescript type name was provided! %v", gutType.Name))
+	}
+
+	settings := map[*ir.Decl]Type{}
+	b := newBuilder(settings)
+	declareNamedStructs(b, t)
+
+	var alias *ir.Decl
+	if gutType.Brand || isBranded(t) {
+		if !canBrand(t) {
+			panic(fmt.Sprintf("Only named types which are not structs can be branded! %v", t))
+		}
+		alias = b.DeclareNamed(t, gutType.Name)
 	} else {
-		s = defaultSettings
+		alias = b.DeclareAlias(gutType.Name, b.Type(t), t)
 	}
 
-	file, err := os.Create(filename)
+	// only the alias is converted with the settings
+	settings[alias] = gutType
+	return emitDecls(b, []*ir.Decl{alias}, settings)
+}
+
+// emitDecls builds the graph and converts the declarations (followed
+// by the ones which were added during the build) into typescript.
+func emitDecls(b *ir.Builder, decls []*ir.Decl, settings map[*ir.Decl]Type) string {
+	graph, err := build(b)
 	if err != nil {
-		return err
+		panic(err)
 	}
-	defer file.Close()
 
-	head_and_interfaces := fmt.Sprintln(createHeader(s), content)
-	_, err = file.Write([]byte(head_and_interfaces))
-	if err != nil {
-		return err
+	order := emitOrder(graph, decls)
+	if err := checkCollisions(order, settings); err != nil {
+		panic(err)
 	}
+	inheritSettings(order, settings)
 
-	fmt.Println("\033[32m * CREATED\033[0m ", filename)
-	return nil
+	e := newTSEmitter(graph, settings)
+
+	sb := strings.Builder{}
+	for _, d := range order {
+		sb.WriteString(e.parseStruct(d))
+	}
+	return sb.String()
+}
+
+// inheritSettings sets the settings of the anonymous structs,
+// which are inherited from the declarations which hold them.
+func inheritSettings(order []*ir.Decl, settings map[*ir.Decl]Type) {
+	for _, d := range order {
+		if _, ok := settings[d]; !ok && d.Parent != nil {
+			settings[d] = rootSettings(settings, d)
+		}
+	}
+}
+
+/* convert the field name into a valid value, based on the json tags */
+func typescriptFieldname(field *ir.Field) string {
+	if field.Optional {
+		return fmt.Sprintf("%v?", field.JSONName)
+	}
+	return field.JSONName
 }
 
+// structIsArray checks if the value is a slice of structs (or of pointers to structs)
 func structIsArray(v interface{}) bool {
-	rv := r.ValueOf(v)
-	if rv.Kind() != r.Slice {
+	t := r.TypeOf(v)
+	if t == nil || t.Kind() != r.Slice {
 		return false
 	}
-	if rv.Type().Elem().Kind() != r.Struct {
+	return arrayElem(t).Kind() == r.Struct
+}
+
+// arrayElem returns the elements of the slice, without the pointers
+func arrayElem(t r.Type) r.Type {
+	elem := t.Elem()
+	for elem.Kind() == r.Ptr {
+		elem = elem.Elem()
+	}
+	return elem
+}
+
+// isTypeAlias checks if the type is converted into a type alias, instead
+// of an interface. Structs and slices of anonymous structs are converted
+// into interfaces, while named slices of named structs are aliases which
+// reference the struct by name.
+func isTypeAlias(t r.Type) bool {
+	switch {
+	case t.Kind() == r.Struct:
 		return false
+	case t.Kind() == r.Slice && arrayElem(t).Kind() == r.Struct:
+		return t.Name() != "" && arrayElem(t).Name() != ""
+	default:
+		return true
 	}
-	return true
 }
 
 // Thanks chatGPT
@@ -302,38 +866,3 @@ func isValidTypeName(name string) bool {
 
 	return true
 }
-
-func createHeader(s Settings) string {
-	sb := strings.Builder{}
-
-	// Append first line if exists
-	if s.FirstLine != "" {
-		sb.WriteString(s.FirstLine)
-	}
-
-	// Check for uuid type
-	if s.UuidType == "" {
-		sb.WriteString(fmt.Sprintf("export type UuidType = %s\n", "string"))
-	} else {
-		sb.WriteString(fmt.Sprintf("export type UuidType = %s\n", s.UuidType))
-	}
-
-	// Check for int64/uint64 types
-	if s.BigIntType == "" {
-		sb.WriteString(fmt.Sprintf("export type BigIntType = %s\n", "BigInt"))
-	} else {
-		sb.WriteString(fmt.Sprintf("export type BigIntType = %s\n", s.BigIntType))
-	}
-
-	// Check for time.Time type
-	if s.DateType == "" {
-		sb.WriteString(fmt.Sprintf("export type DateType = %s\n", "Date"))
-	} else {
-		sb.WriteString(fmt.Sprintf("export type DateType = %s\n", s.DateType))
-	}
-
-	// seperate type definitions from the generated interfaces
-	sb.WriteString("\n\n")
-
-	return sb.String()
-}
@@ -39,7 +39,7 @@ func TestTypescriptCodegen(t *testing.T) {
 			export interface SimpleStructWithTimeFields {
 				MyString: string
 				CreatedAt: DateType
-				updated_at?: DateType
+				updated_at: DateType
 				deleted_at: DateType
 			}`,
 		},
@@ -49,7 +49,7 @@ func TestTypescriptCodegen(t *testing.T) {
 			expected_interface: `
 			export interface StructWithTimeDurationField {
 				SomeValue: string
-				CurrentTime: BigIntType
+				CurrentTime: DurationType
 			}`,
 		},
 
@@ -75,7 +75,7 @@ func TestTypescriptCodegen(t *testing.T) {
 				  my_float: number
 				  timestamp: number
 				}
-				opt_ref?: {
+				opt_ref: {
 				  my_float: number
 				  timestamp: number
 				}
@@ -96,10 +96,8 @@ func TestTypescriptCodegen(t *testing.T) {
 			expected_interface: `
 			export interface StructWithUnspecifiedStructName {
 				SomeValue: string
-				ReferenceStruct: {
-				  my_float: number
-				  timestamp: number
-				}
+				my_float: number
+				timestamp: number
 			}`,
 		},
 		{
@@ -117,9 +115,9 @@ func TestTypescriptCodegen(t *testing.T) {
 		{
 			generated_interface: Convert(Employees{}),
 			expected_interface: `
-			export type EmployeesArray = Employees[]
+			export type Employees = EmployeesItem[]
 
-			export interface Employees {
+			export interface EmployeesItem {
 			  user_id: UuidType
 			  Username: string
 			  opt_surename?: string
@@ -261,6 +259,170 @@ func TestTypescriptCodegen(t *testing.T) {
 				}
 			}`,
 		},
+		/* Tests on the names of the generics */
+		{
+			generated_interface: Convert(Page[Pair[string, int]]{}),
+			expected_interface: `
+			export interface PageOfPairOfStringAndInt {
+				items: {
+					key: string
+					value: number
+				}[]
+				total: number
+			}`,
+		},
+		/* Tests on anonymous structs */
+		{
+			generated_interface: Convert(Order{}, Type{DeclareAnonymous: true}),
+			expected_interface: `
+			export interface Order {
+				id: number
+				customer: OrderCustomer
+				items: OrderItems[]
+				billing?: OrderCustomer
+			}
+
+			export interface OrderCustomer {
+				name: string
+				address: OrderCustomerAddress
+			}
+
+			export interface OrderItems {
+				sku: string
+				qty: number
+			}
+
+			export interface OrderCustomerAddress {
+				city: string
+			}`,
+		},
+		{
+			generated_interface: Convert(Employees{}, Type{
+				Name:          "Employee",
+				ArrayTypeName: "Employees",
+				AnonymousName: func(parent, field string) string { return "Unused" },
+			}),
+			expected_interface: `
+			export type Employees = Employee[]
+
+			export interface Employee {
+				user_id: UuidType
+				Username: string
+				opt_surename?: string
+				RandomInterface: any
+				opt_interface?: any
+			}`,
+		},
+		/* Tests on func types */
+		{
+			generated_interface: Convert(Plugin{}),
+			expected_interface: `
+			export interface Plugin {
+				name: string
+				on_event: (arg0: any, arg1: string) => void
+				transform: (arg0: string, ...arg1: string[]) => [string, BigIntType]
+				validate?: (arg0: any) => void
+				on_error: (arg0: ErrorType) => void
+				listeners: (() => boolean)[]
+			}`,
+		},
+		/* Tests on named types which are not structs */
+		{
+			generated_interface: Convert(UserIndex{}),
+			expected_interface: `
+			export type UserIndex = {[key: string]: SimpleStructWithTimeFields}
+
+			export interface SimpleStructWithTimeFields {
+				MyString: string
+				CreatedAt: DateType
+				updated_at: DateType
+				deleted_at: DateType
+			}`,
+		},
+		{
+			generated_interface: Convert(IDs{}),
+			expected_interface:  `export type IDs = BigIntType[]`,
+		},
+		{
+			generated_interface: Convert(IDs{}, Type{Name: "UserIDs"}),
+			expected_interface:  `export type UserIDs = BigIntType[]`,
+		},
+		{
+			generated_interface: Convert(UserPointers{}),
+			expected_interface: `
+			export type UserPointers = SimpleStruct[]
+
+			export interface SimpleStruct {
+				MyString: string
+			}`,
+		},
+		{
+			generated_interface: Convert(Status("")),
+			expected_interface:  `export type Status = string`,
+		},
+		{
+			generated_interface: Convert(Matrix{}),
+			expected_interface:  `export type Matrix = number[][]`,
+		},
+		{
+			generated_interface: Convert(PluginHook(nil)),
+			expected_interface:  `export type PluginHook = (arg0: any, arg1: string) => void`,
+		},
+		{
+			// slices of anonymous structs are still converted into interfaces
+			generated_interface: Convert(EmployeePointers{}),
+			expected_interface: `
+			export type EmployeePointers = EmployeePointersItem[]
+
+			export interface EmployeePointersItem {
+				name: string
+			}`,
+		},
+		/* Optional fields follow encoding/json (omitempty / omitzero) */
+		{
+			generated_interface: Convert(StructWithOmittedFields{}, Type{Name: "OmittedFields"}),
+			expected_interface: `
+			export interface OmittedFields {
+				str?: string
+				num?: number
+				ptr?: {
+					my_float: number
+					timestamp: number
+				}
+				slice?: string[]
+				map?: {[key: string]: string}
+				iface?: any
+				array: number[]
+				empty_array?: number[]
+				ref: {
+					my_float: number
+					timestamp: number
+				}
+				time: DateType
+				zero_num?: number
+				zero_array?: number[]
+				zero_ref?: {
+					my_float: number
+					timestamp: number
+				}
+				zero_time?: DateType
+				zero_date?: DateType
+				required_ref: {
+					my_float: number
+					timestamp: number
+				}
+				required_slice: string[]
+			}`,
+		},
+		{
+			generated_interface: Convert(StructWithBytes{}),
+			expected_interface: `
+			export interface StructWithBytes {
+				data: string
+				chunks: string[]
+				checksum: number[]
+			}`,
+		},
 	}
 
 	for _, tc := range tests {
@@ -272,6 +434,28 @@ func TestTypescriptCodegen(t *testing.T) {
 	}
 }
 
+func TestInvalidNamedTypes(t *testing.T) {
+	tests := []func(){
+		func() { Convert(nil) },
+		func() { Convert(map[string]int{}) },
+		func() { Convert(Status(""), Type{Name: "class"}) },
+		func() { Convert(make(chan int)) },
+		// anonymous structs do not have a name
+		func() { Convert(struct{ A int }{}) },
+	}
+
+	for i, fn := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic in test %d", i)
+				}
+			}()
+			fn()
+		}()
+	}
+}
+
 // util func for removig whitespaces, so that you can compare
 // if the generated code matches the expected string.
 func stripSpaces(str string) string {
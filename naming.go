@@ -0,0 +1,105 @@
+package gut
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/tompston/gut/ir"
+)
+
+// NamingStrategy tells the Registry how to handle the types from
+// different Go packages, which have the same name.
+type NamingStrategy int
+
+const (
+	// The registry panics (Convert) or returns an error (Generate), if
+	// the types with the same name would be declared in the same file,
+	// or would be imported into the same module.
+	NamingError NamingStrategy = iota
+	// The names of the types are prefixed with the name of their
+	// package (billing.User -> BillingUser), if they collide.
+	NamingPrefix
+	// Registry.Convert wraps the types of every module (by default one
+	// per Go package) in an `export namespace <module> { ... }` and the
+	// references across the modules are qualified (billing.User). The
+	// files written by Registry.Generate are already separate modules.
+	NamingNamespace
+)
+
+// prefixCollisions prefixes the names of the declarations from different
+// packages, which have the same name, with the names of their packages.
+func prefixCollisions(decls []*ir.Decl) {
+	packages := make(map[string]map[string]bool)
+	for _, d := range decls {
+		if packages[d.Name] == nil {
+			packages[d.Name] = make(map[string]bool)
+		}
+		packages[d.Name][d.PkgPath] = true
+	}
+
+	for _, d := range decls {
+		if len(packages[d.Name]) > 1 {
+			d.Name = packagePrefix(d.PkgPath) + d.Name
+		}
+	}
+}
+
+// packagePrefix returns the PascalCase name of the package (e.g. "Billing")
+func packagePrefix(pkgPath string) string {
+	name := camelCase(path.Base(pkgPath))
+	if pkgPath == "" || name == "" {
+		return ""
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// convertNamespaces converts the registered structs into a single string,
+// in which the declarations of every module are wrapped in a namespace.
+func (reg *Registry) convertNamespaces(b *registryBuild) (string, error) {
+	var order []string
+	grouped := make(map[string][]*ir.Decl)
+	for _, d := range b.order {
+		name := b.modules[d]
+		if _, ok := grouped[name]; !ok {
+			if !isValidTypeName(name) {
+				return "", fmt.Errorf("gut: %q is not a valid typescript namespace", name)
+			}
+			order = append(order, name)
+		}
+		grouped[name] = append(grouped[name], d)
+	}
+
+	e := newTSEmitter(b.graph, b.settings)
+	e.namespaces = b.modules
+
+	sb := strings.Builder{}
+	for _, name := range order {
+		if err := checkCollisions(grouped[name], b.settings); err != nil {
+			return "", err
+		}
+
+		e.namespace = name
+		body := strings.Builder{}
+		for _, d := range grouped[name] {
+			body.WriteString(e.parseStruct(d))
+		}
+		sb.WriteString(fmt.Sprintf("export namespace %s {\n%s}\n\n", name, indentLines(strings.TrimRight(body.String(), "\n")+"\n", "  ")))
+	}
+
+	// the clients are declared outside of the namespaces
+	e.namespace = ""
+	sb.WriteString(e.client(b.graph))
+	return sb.String(), nil
+}
+
+// indentLines indents the lines of the content, which are not empty
+func indentLines(content string, indent string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
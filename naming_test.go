@@ -0,0 +1,118 @@
+package gut
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tompston/gut/types/auth"
+	"github.com/tompston/gut/types/billing"
+)
+
+func collidingRegistry(naming NamingStrategy) *Registry {
+	reg := NewRegistry().Add(billing.User{}).Add(auth.User{}, Type{Guard: true})
+	reg.Naming = naming
+	return reg
+}
+
+func TestNamingError(t *testing.T) {
+	err := collidingRegistry(NamingError).GenerateFS(NewMemFS(), Settings{Logger: DiscardLogger})
+	if err == nil || !strings.Contains(err.Error(), "Registry.Naming") {
+		t.Errorf("expected an error about the colliding imports, got %v", err)
+	}
+
+	defer func() {
+		if err := recover(); err == nil || !strings.Contains(fmt.Sprint(err), "User is declared more than once") {
+			t.Errorf("expected a collision, got %v", err)
+		}
+	}()
+	collidingRegistry(NamingError).Convert()
+}
+
+func TestNamingPrefix(t *testing.T) {
+	generated := collidingRegistry(NamingPrefix).Convert()
+
+	expected := `
+	export interface BillingUser {
+		id: number
+		account: AuthUser
+		config: {
+			currency: string
+		}
+	}
+
+	export interface AuthUser {
+		email: string
+		config: {
+			mfa: boolean
+		}
+	}`
+	if !strings.HasPrefix(stripSpaces(generated), stripSpaces(expected)) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+	if !containsAll(generated, "export function isAuthUser(x: unknown): x is AuthUser {") {
+		t.Errorf("expected the guard of the prefixed type, got:\n%v", generated)
+	}
+}
+
+func TestNamingNamespace(t *testing.T) {
+	reg := collidingRegistry(NamingNamespace)
+	generated := reg.Convert()
+
+	expected := `
+	export namespace billing {
+		export interface User {
+			id: number
+			account: auth.User
+			config: {
+				currency: string
+			}
+		}
+	}
+
+	export namespace auth {
+		export interface User {
+			email: string
+			config: {
+				mfa: boolean
+			}
+		}
+
+		export function isUser(x: unknown): x is User {`
+	if !strings.HasPrefix(stripSpaces(generated), stripSpaces(expected)) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+
+	reg.Index = true
+	out := NewMemFS()
+	if err := reg.GenerateFS(out, Settings{Logger: DiscardLogger}); err != nil {
+		t.Fatal(err)
+	}
+
+	billingModule, _ := out.ReadFile("billing.ts")
+	if !containsAll(string(billingModule), `import type * as auth from "./auth"`, "account: auth.User") {
+		t.Errorf("unexpected billing.ts:\n%s", billingModule)
+	}
+
+	index, _ := out.ReadFile("index.ts")
+	if !containsAll(string(index), `export * as billing from "./billing"`, `export * as auth from "./auth"`) {
+		t.Errorf("unexpected index.ts:\n%s", index)
+	}
+}
+
+func TestPackagePrefix(t *testing.T) {
+	tests := []struct {
+		pkgPath, expected string
+	}{
+		{"github.com/acme/billing", "Billing"},
+		{"github.com/acme/user_accounts", "UserAccounts"},
+		{"main", "Main"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := packagePrefix(tt.pkgPath); got != tt.expected {
+			t.Errorf("packagePrefix(%q) = %q, expected %q", tt.pkgPath, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package gut
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tompston/gut/ir"
+)
+
+// openAPIEmitter emits an OpenAPI 3.1 document, with the declarations in
+// "components/schemas" and the endpoints of the graph in "paths". The
+// schemas are collected while the declarations are emitted and the whole
+// document is written in the footer.
+type openAPIEmitter struct {
+	schemas *jsonSchemaEmitter
+	s       Settings
+	yaml    bool
+	// compact "name": schema pairs of the declarations
+	decls []string
+}
+
+func newOpenAPIEmitter(s Settings) Emitter {
+	return &openAPIEmitter{schemas: &jsonSchemaEmitter{s: s, refPrefix: "#/components/schemas/"}, s: s}
+}
+
+func newOpenAPIYAMLEmitter(s Settings) Emitter {
+	e := newOpenAPIEmitter(s).(*openAPIEmitter)
+	e.yaml = true
+	return e
+}
+
+func (e *openAPIEmitter) Header(*ir.Graph) string { return "" }
+
+func (e *openAPIEmitter) EmitField(d *ir.Decl, f *ir.Field) string {
+	return e.schemas.EmitField(d, f)
+}
+
+func (e *openAPIEmitter) EmitDecl(d *ir.Decl, fields []string) string {
+	e.decls = append(e.decls, fmt.Sprintf("%s:%s", quote(d.Name), e.schemas.declSchema(d, fields)))
+	return ""
+}
+
+func (e *openAPIEmitter) Footer(g *ir.Graph) string {
+	doc := fmt.Sprintf(`{"openapi":"3.1.0","info":{"title":%s,"version":%s},"paths":{%s},"components":{"schemas":{%s}}}`,
+		quote(valueOr(e.s.APITitle, "API")),
+		quote(valueOr(e.s.APIVersion, "0.0.0")),
+		e.paths(g.Endpoints),
+		strings.Join(e.decls, ","),
+	)
+
+	if e.yaml {
+		out, err := jsonToYAML([]byte(doc))
+		if err != nil {
+			panic(fmt.Sprintf("Invalid openapi document! %v", err))
+		}
+		return out
+	}
+
+	var buffer bytes.Buffer
+	if err := json.Indent(&buffer, []byte(doc), "", "  "); err != nil {
+		panic(fmt.Sprintf("Invalid openapi document! %v", err))
+	}
+	buffer.WriteString("\n")
+	return buffer.String()
+}
+
+// paths returns the compact path items of the endpoints, in which
+// the endpoints with the same path are grouped together.
+func (e *openAPIEmitter) paths(endpoints []*ir.Endpoint) string {
+	var order []string
+	operations := make(map[string][]string)
+
+	for _, ep := range endpoints {
+		path := pathParamExp.ReplaceAllString(ep.Path, "{$1$2}")
+		if _, ok := operations[path]; !ok {
+			order = append(order, path)
+		}
+		operations[path] = append(operations[path], fmt.Sprintf("%s:%s", quote(strings.ToLower(ep.Method)), e.operation(ep)))
+	}
+
+	items := make([]string, 0, len(order))
+	for _, path := range order {
+		items = append(items, fmt.Sprintf("%s:{%s}", quote(path), strings.Join(operations[path], ",")))
+	}
+	return strings.Join(items, ",")
+}
+
+// operation returns the compact operation object of the endpoint
+func (e *openAPIEmitter) operation(ep *ir.Endpoint) string {
+	op := []string{fmt.Sprintf(`"operationId":%s`, quote(ep.Name))}
+
+	var params []string
+	for _, name := range pathParams(ep.Path) {
+		params = append(params, fmt.Sprintf(`{"name":%s,"in":"path","required":true,"schema":{"type":"string"}}`, quote(name)))
+	}
+	if ep.Query != nil {
+		for _, f := range flattenFields(queryFields(ep.Query)) {
+			params = append(params, fmt.Sprintf(`{"name":%s,"in":"query","required":%v,"schema":%s}`, quote(f.JSONName), !f.Optional, e.schemas.schema(f.Type, f.Stringified)))
+		}
+	}
+	if len(params) > 0 {
+		op = append(op, fmt.Sprintf(`"parameters":[%s]`, strings.Join(params, ",")))
+	}
+
+	if ep.Request != nil {
+		op = append(op, fmt.Sprintf(`"requestBody":{"required":true,"content":{"application/json":{"schema":%s}}}`, e.schemas.schema(ep.Request, false)))
+	}
+
+	if ep.Response != nil {
+		op = append(op, fmt.Sprintf(`"responses":{"200":{"description":"OK","content":{"application/json":{"schema":%s}}}}`, e.schemas.schema(ep.Response, false)))
+	} else {
+		op = append(op, `"responses":{"204":{"description":"No Content"}}`)
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(op, ","))
+}
+
+// queryFields returns the fields of the query type (object or a struct)
+func queryFields(typ *ir.Type) []*ir.Field {
+	switch typ = typ.Unwrap(); typ.Kind {
+	case ir.Object:
+		return typ.Fields
+	case ir.Reference:
+		if typ.Decl.Kind == ir.StructDecl {
+			return typ.Decl.Fields
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package gut
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/tompston/gut/types"
+)
+
+func openAPIRegistry() *Registry {
+	return NewRegistry().
+		Add(SimpleStructWithTimeFields{}).
+		Add(Endpoint{Path: "/users/{id}", Response: SimpleStructWithTimeFields{}}).
+		Add(Endpoint{Path: "/users", Query: ListUsersQuery{}, Response: []SimpleStructWithTimeFields{}}).
+		Add(Endpoint{Method: "post", Path: "/users", Request: CreateUserRequest{}, Response: ReferenceStruct{}}).
+		Add(Endpoint{Name: "deleteUser", Method: "DELETE", Path: "/users/:id"})
+}
+
+func TestOpenAPIEmitter(t *testing.T) {
+	generated, err := openAPIRegistry().Emit("openapi", Settings{APITitle: "Users", APIVersion: "1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(generated), &doc); err != nil {
+		t.Fatalf("expected a valid json document, got %v:\n%v", err, generated)
+	}
+
+	compact := stripSpaces(generated)
+	for _, expected := range []string{
+		`"openapi":"3.1.0"`,
+		`"info":{"title":"Users","version":"1.0.0"}`,
+		`"SimpleStructWithTimeFields":{"type":"object"`,
+		`"CreateUserRequest":{"type":"object","properties":{"username":{"type":"string"}},"required":["username"]}`,
+		`"/users/{id}":{"get":{"operationId":"getUsersById","parameters":[{"name":"id","in":"path","required":true,"schema":{"type":"string"}}]`,
+		`"schema":{"$ref":"#/components/schemas/SimpleStructWithTimeFields"}`,
+		`{"name":"page","in":"query","required":false,"schema":{"type":"integer"}}`,
+		`"schema":{"type":"array","items":{"$ref":"#/components/schemas/SimpleStructWithTimeFields"}}`,
+		`"post":{"operationId":"postUsers","requestBody":{"required":true,"content":{"application/json":{"schema":{"$ref":"#/components/schemas/CreateUserRequest"}}}}`,
+		`"delete":{"operationId":"deleteUser"`,
+		`"responses":{"204":{"description":"NoContent"}}`,
+	} {
+		if !strings.Contains(compact, stripSpaces(expected)) {
+			t.Errorf("expected the output to contain\n%v\ngot:\n%v", expected, generated)
+		}
+	}
+
+	// endpoints with the same path are grouped
+	if strings.Count(generated, `"/users/{id}"`) != 1 {
+		t.Errorf("expected the /users/{id} path once, got:\n%v", generated)
+	}
+}
+
+func TestOpenAPIYAMLEmitter(t *testing.T) {
+	generated, err := openAPIRegistry().Emit("openapi-yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"openapi: \"3.1.0\"\ninfo:\n  title: API\n  version: \"0.0.0\"\npaths:\n  /users/{id}:\n    get:\n      operationId: getUsersById\n",
+		"        - name: id\n          in: path\n          required: true\n",
+		"        \"204\":\n          description: \"No Content\"\n",
+		"components:\n  schemas:\n    SimpleStructWithTimeFields:\n      type: object\n",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("expected the output to contain\n%v\ngot:\n%v", expected, generated)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package gut
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Output is the destination of the generated files. It is a readable
+// fs.FS, which can also be written to. Names are slash separated paths.
+type Output interface {
+	fs.ReadFileFS
+	// WriteFile creates or replaces the file with the passed in data.
+	WriteFile(name string, data []byte) error
+}
+
+// osOutput writes the files to the OS filesystem, using the names as they are.
+var osOutput Output = dirOutput{}
+
+// Dir returns an Output which writes the files to the directory on
+// the OS filesystem. Missing parent directories are created.
+func Dir(dir string) Output {
+	return dirOutput{root: dir}
+}
+
+type dirOutput struct {
+	root string
+}
+
+func (d dirOutput) path(name string) string {
+	if d.root == "" {
+		return name
+	}
+	return filepath.Join(d.root, filepath.FromSlash(name))
+}
+
+func (d dirOutput) Open(name string) (fs.File, error) {
+	return os.Open(d.path(name))
+}
+
+func (d dirOutput) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(d.path(name))
+}
+
+// WriteFile writes the data atomically, by creating a temporary
+// file in the same directory and renaming it to the filename, so
+// that readers never observe a partially written file.
+func (d dirOutput) WriteFile(name string, data []byte) error {
+	filename := d.path(name)
+
+	perm := fs.FileMode(0644)
+	if info, err := os.Stat(filename); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	} else if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// the temp file is removed only if something went wrong,
+	// because after the rename it does not exist anymore.
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+// MemFS is an in-memory Output, which is useful for tests or for
+// serving / embedding the generated files without touching the disk.
+// The zero value is ready to use.
+type MemFS struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewMemFS returns an empty in-memory Output.
+func NewMemFS() *MemFS {
+	return &MemFS{}
+}
+
+// Open opens the file, or the directory which holds files. The opened
+// file holds a snapshot of the data, so later writes do not change it.
+func (m *MemFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if data, ok := m.files[name]; ok {
+		info := memInfo{name: path.Base(name), size: int64(len(data))}
+		return &memFile{info: info, Reader: bytes.NewReader(data)}, nil
+	}
+
+	// the directories are not stored, they exist if they hold files
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+
+	children := make(map[string]bool)
+	for file := range m.files {
+		if !strings.HasPrefix(file, prefix) {
+			continue
+		}
+		child, rest, nested := strings.Cut(strings.TrimPrefix(file, prefix), "/")
+		children[child] = children[child] || nested && rest != ""
+	}
+	if len(children) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, child := range sortedKeys(children) {
+		info := memInfo{name: child, dir: children[child]}
+		if !info.dir {
+			info.size = int64(len(m.files[prefix+child]))
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	return &memDir{info: memInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (m *MemFS) WriteFile(name string, data []byte) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.files == nil {
+		m.files = make(map[string][]byte)
+	}
+	m.files[path.Clean(name)] = append([]byte(nil), data...)
+	return nil
+}
+
+// Names returns the sorted names of all of the files in the MemFS.
+func (m *MemFS) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// memInfo describes a file or a directory of the MemFS
+type memInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return time.Time{} }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() interface{}   { return nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+// memFile is an opened file of the MemFS
+type memFile struct {
+	*bytes.Reader
+	info memInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+// memDir is an opened directory of the MemFS
+type memDir struct {
+	info    memInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir returns the next n entries of the directory (or all of the
+// remaining entries, if n <= 0), as described by fs.ReadDirFile.
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return remaining[:n], nil
+}
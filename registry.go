@@ -0,0 +1,669 @@
+package gut
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	r "reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/tompston/gut/ir"
+)
+
+// Registry holds a set of structs which are converted together. Every
+// struct which is added to the registry is emitted as a separate
+// declaration and is referenced by name from the other structs (instead
+// of being inlined), which makes it possible to split the generated
+// interfaces into multiple typescript modules.
+//
+// Example
+//
+//	reg := gut.NewRegistry()
+//	reg.Add(User{}).Add(ReferenceStruct{}, gut.Type{Module: "common"})
+//
+//	// writes ./frontend/types/<module>.ts for every module
+//	err := reg.Generate("./frontend/types")
+type Registry struct {
+	// Optional map of Go package paths to the names of the typescript
+	// modules in which the types of the package are declared. If the
+	// package is not present in the map, the name of the package is used.
+	Modules map[string]string
+	// if set to true, an index.ts file which re-exports the types
+	// of all of the modules is also generated. (Default = false)
+	Index bool
+	// Optional file extension of the generated modules. (Default = ".ts")
+	Extension string
+	// Optional map of filenames to the names of the emitters (see
+	// RegisterEmitter), which are written together with the typescript
+	// modules. (e.g. {"schema.json": "jsonschema"})
+	Outputs map[string]string
+	// Optional map of filenames to the templates (see NewTemplate), which
+	// are executed with the registered structs and written together with
+	// the typescript modules.
+	Templates map[string]*template.Template
+	// Optional name of the module in which the clients of the
+	// endpoints and of the services are declared. (Default = "client")
+	Client string
+	// Tells how the types from different Go packages, which have the
+	// same name, are handled. (Default = NamingError)
+	Naming NamingStrategy
+
+	entries   []registryEntry
+	endpoints []Endpoint
+	services  []registryService
+}
+
+type registryEntry struct {
+	typ      r.Type // struct which is declared
+	pkgPath  string // package path of the registered type
+	settings Type
+	union    *UnionType
+	enum     *EnumType
+}
+
+type registryService struct {
+	service ServiceType
+	name    string
+}
+
+// module is a single generated typescript file
+type module struct {
+	name    string
+	content string
+	// names of the exported declarations
+	exports []string
+	// names of the exported values (functions and classes)
+	values []string
+	// aliases and helpers which are declared in the header
+	used usage
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Add registers the struct (or an array of structs, a Union, an Enum, an
+// Endpoint or a Service) in the registry. The optional 2nd param can be used to modify
+// the generated interface, in the same way as with the Convert function.
+func (reg *Registry) Add(i interface{}, typeSettings ...Type) *Registry {
+	typ := r.TypeOf(i)
+
+	var settings Type
+	if len(typeSettings) == 1 {
+		settings = typeSettings[0]
+	}
+
+	if ep, ok := i.(Endpoint); ok {
+		ep = ep.normalize()
+		for _, existing := range reg.endpoints {
+			if existing.Name == ep.Name {
+				panic(fmt.Sprintf("The endpoint %v is added more than once!", ep.Name))
+			}
+		}
+		reg.endpoints = append(reg.endpoints, ep)
+		return reg
+	}
+
+	if svc, ok := i.(ServiceType); ok {
+		if settings.Name == "" {
+			settings.Name = ir.TypeName(svc.typ)
+		}
+		if !isValidTypeName(settings.Name) {
+			panic(fmt.Sprintf("Invalid typescript service name was provided! %v", settings.Name))
+		}
+		for _, existing := range reg.services {
+			if existing.name == settings.Name {
+				panic(fmt.Sprintf("The service %v is added more than once!", settings.Name))
+			}
+		}
+		reg.services = append(reg.services, registryService{service: svc, name: settings.Name})
+		return reg
+	}
+
+	if u, ok := i.(UnionType); ok {
+		if settings.Name == "" {
+			settings.Name = ir.TypeName(u.typ)
+		}
+		if !isValidTypeName(settings.Name) {
+			panic(fmt.Sprintf("Invalid typescript union name was provided! %v", settings.Name))
+		}
+		reg.entries = append(reg.entries, registryEntry{typ: u.typ, pkgPath: u.typ.PkgPath(), settings: settings, union: &u})
+		return reg
+	}
+
+	if en, ok := i.(EnumType); ok {
+		if settings.Name == "" {
+			settings.Name = ir.TypeName(en.typ)
+		}
+		if !isValidTypeName(settings.Name) {
+			panic(fmt.Sprintf("Invalid typescript enum name was provided! %v", settings.Name))
+		}
+		reg.entries = append(reg.entries, registryEntry{typ: en.typ, pkgPath: en.typ.PkgPath(), settings: settings, enum: &en})
+		return reg
+	}
+
+	if typ == nil {
+		panic("Only structs or arrays of structs can be added to the registry! <nil>")
+	}
+
+	entry := registryEntry{typ: typ, pkgPath: typ.PkgPath()}
+
+	if structIsArray(i) {
+		settings.IsArray = true
+		named := settings.Name != ""
+		if !named {
+			settings.Name = ir.TypeName(typ)
+		}
+		settings = nameArrayItems(settings, typ, named)
+		entry.typ = arrayElem(typ)
+	} else if typ.Kind() != r.Struct {
+		panic(fmt.Sprintf("Only structs or arrays of structs can be added to the registry! %v", typ))
+	}
+
+	if settings.Name == "" {
+		settings.Name = ir.TypeName(entry.typ)
+	}
+	if !isValidTypeName(settings.Name) {
+		panic(fmt.Sprintf("Invalid typescript interface name was provided! %v", settings.Name))
+	}
+
+	entry.settings = settings
+	reg.entries = append(reg.entries, entry)
+	return reg
+}
+
+// Convert converts all of the registered structs into typescript
+// interfaces and returns them as a single string.
+func (reg *Registry) Convert() string {
+	b, err := reg.build()
+	if err != nil {
+		panic(err)
+	}
+
+	if reg.Naming == NamingNamespace {
+		content, err := reg.convertNamespaces(b)
+		if err != nil {
+			panic(err)
+		}
+		return content
+	}
+
+	// the declarations are emitted into a single string
+	if err := checkCollisions(b.order, b.settings); err != nil {
+		panic(err)
+	}
+
+	e := newTSEmitter(b.graph, b.settings)
+
+	sb := strings.Builder{}
+	for _, d := range b.order {
+		sb.WriteString(e.parseStruct(d))
+	}
+	sb.WriteString(e.client(b.graph))
+	return sb.String()
+}
+
+// Emit converts all of the registered structs with the emitter which is
+// registered under the name and returns the output as a single file.
+//
+// Example
+//
+//	schema, err := reg.Emit("jsonschema")
+func (reg *Registry) Emit(name string, settings ...Settings) (string, error) {
+	em, err := newEmitter(name, getSettings(settings))
+	if err != nil {
+		return "", err
+	}
+
+	b, err := reg.build()
+	if err != nil {
+		return "", err
+	}
+
+	if ts, ok := em.(*typescriptEmitter); ok {
+		ts.graph = b.graph
+		ts.settings = b.settings
+	}
+	return emit(em, b.graph, b.order), nil
+}
+
+// Generate creates a typescript module in the passed in directory for
+// every module of the registry. The header of every module is created
+// from the settings, in the same way as with the Generate function.
+func (reg *Registry) Generate(dir string, settings ...Settings) error {
+	return reg.GenerateFS(Dir(dir), settings...)
+}
+
+// GenerateFS works like Generate, but writes the modules to the
+// passed in Output.
+func (reg *Registry) GenerateFS(out Output, settings ...Settings) error {
+	s := getSettings(settings)
+
+	files, err := reg.files(s)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range sortedKeys(files) {
+		if err := writeGenerated(out, name, []byte(files[name]), s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check returns the diff of all of the modules which are stale in
+// the passed in directory. An empty string means that all of the
+// modules are up to date.
+func (reg *Registry) Check(dir string, settings ...Settings) (string, error) {
+	return reg.CheckFS(Dir(dir), settings...)
+}
+
+// CheckFS works like Check, but reads the modules from the passed in Output.
+func (reg *Registry) CheckFS(out Output, settings ...Settings) (string, error) {
+	files, err := reg.files(getSettings(settings))
+	if err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+	for _, name := range sortedKeys(files) {
+		current, err := out.ReadFile(name)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		sb.WriteString(unifiedDiff(name, string(current), files[name]))
+	}
+	return sb.String(), nil
+}
+
+// files returns the content of every generated file, keyed by the filename.
+func (reg *Registry) files(s Settings) (map[string]string, error) {
+	modules, err := reg.modules(s)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string, len(modules)+1)
+	index := strings.Builder{}
+
+	for _, m := range modules {
+		files[reg.filename(m.name)] = fmt.Sprintln(usedHeader(s, m.used, false), m.content)
+		if reg.Naming == NamingNamespace && m.name != reg.clientModule() {
+			// the names of the modules may collide, so they are re-exported as namespaces
+			index.WriteString(fmt.Sprintf("export * as %s from \"%s\"\n", m.name, reg.importPath(m.name)))
+			continue
+		}
+		if len(m.exports) > 0 {
+			index.WriteString(fmt.Sprintf("export type { %s } from \"%s\"\n", strings.Join(m.exports, ", "), reg.importPath(m.name)))
+		}
+		if len(m.values) > 0 {
+			index.WriteString(fmt.Sprintf("export { %s } from \"%s\"\n", strings.Join(m.values, ", "), reg.importPath(m.name)))
+		}
+	}
+
+	if reg.Index {
+		if _, ok := files[reg.filename("index")]; ok {
+			return nil, fmt.Errorf("gut: the index file collides with the module called index")
+		}
+		files[reg.filename("index")] = index.String()
+	}
+
+	for _, name := range sortedKeys(reg.Outputs) {
+		if _, ok := files[name]; ok {
+			return nil, fmt.Errorf("gut: the output %v collides with a generated module", name)
+		}
+		content, err := reg.Emit(reg.Outputs[name], s)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = content
+	}
+
+	for _, name := range sortedKeys(reg.Templates) {
+		if _, ok := files[name]; ok {
+			return nil, fmt.Errorf("gut: the template %v collides with a generated file", name)
+		}
+		sb := strings.Builder{}
+		if err := reg.RenderTemplate(&sb, reg.Templates[name]); err != nil {
+			return nil, err
+		}
+		files[name] = sb.String()
+	}
+
+	return files, nil
+}
+
+// registryBuild is the result of converting the registered structs.
+type registryBuild struct {
+	graph *ir.Graph
+	// declarations in the order of emission
+	order []*ir.Decl
+	// settings of the declarations which were created from the registered structs
+	settings map[*ir.Decl]Type
+	// module in which every declaration is located
+	modules map[*ir.Decl]string
+}
+
+// build converts the registered structs into the ir.Graph
+func (reg *Registry) build() (*registryBuild, error) {
+	b := &registryBuild{
+		settings: make(map[*ir.Decl]Type),
+		modules:  make(map[*ir.Decl]string),
+	}
+	builder := newBuilder(b.settings)
+
+	// structs which were declared implicitly (variants of the unions
+	// and bases of the interfaces), which can also be added on their own
+	implicit := make(map[*ir.Decl]bool)
+	ordered := make(map[*ir.Decl]bool)
+
+	for _, e := range reg.entries {
+		var decls []*ir.Decl
+
+		switch d := builder.Decl(e.typ); {
+		case e.enum != nil:
+			decls = []*ir.Decl{builder.DeclareEnum(e.typ, e.settings.Name, e.enum.values, e.enum.labels)}
+		case e.union != nil:
+			decls = declareUnion(builder, *e.union, e.settings.Name)
+			for _, v := range decls[1:] {
+				implicit[v] = true
+			}
+		case implicit[d] && !e.settings.IsArray:
+			// the struct was already declared implicitly,
+			// so only its settings are updated
+			delete(implicit, d)
+			d.Name = e.settings.Name
+			if !ordered[d] {
+				decls = []*ir.Decl{d}
+			}
+			b.settings[d] = e.settings
+			b.modules[d] = reg.moduleName(e.settings.Module, e.pkgPath)
+		default:
+			decls = declare(builder, e.typ, e.settings)
+		}
+
+		if e.settings.Extends && e.union == nil && e.enum == nil {
+			for _, base := range declareBases(builder, e.typ) {
+				implicit[base] = true
+			}
+		}
+
+		for _, d := range decls {
+			ordered[d] = true
+			b.order = append(b.order, d)
+			b.settings[d] = e.settings
+			b.modules[d] = reg.moduleName(e.settings.Module, e.pkgPath)
+		}
+	}
+
+	for _, ep := range reg.endpoints {
+		for _, d := range declareEndpointTypes(builder, ep) {
+			implicit[d] = true
+		}
+	}
+	for _, svc := range reg.services {
+		for _, d := range declareServiceTypes(builder, svc.service) {
+			implicit[d] = true
+		}
+	}
+	for _, ep := range reg.endpoints {
+		addEndpoint(builder, ep)
+	}
+	for _, svc := range reg.services {
+		addService(builder, svc.service, svc.name)
+	}
+
+	graph, err := build(builder)
+	if err != nil {
+		return nil, err
+	}
+
+	b.graph = graph
+	b.order = emitOrder(graph, b.order)
+	inheritSettings(b.order, b.settings)
+	for _, d := range b.order {
+		if _, ok := b.modules[d]; ok {
+			continue
+		}
+		if d.Parent != nil {
+			// anonymous structs are declared next to their parent
+			b.modules[d] = b.modules[d.Parent]
+		} else {
+			b.modules[d] = reg.moduleName("", d.PkgPath)
+		}
+	}
+
+	if reg.Naming == NamingPrefix {
+		prefixCollisions(b.order)
+	}
+
+	return b, nil
+}
+
+// modules groups the declarations by their module, converts
+// them and adds the imports of the types from the other modules.
+func (reg *Registry) modules(s Settings) ([]module, error) {
+	b, err := reg.build()
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+	grouped := make(map[string][]*ir.Decl)
+
+	for _, d := range b.order {
+		name := b.modules[d]
+		if _, ok := grouped[name]; !ok {
+			order = append(order, name)
+		}
+		grouped[name] = append(grouped[name], d)
+	}
+
+	modules := make([]module, 0, len(order))
+
+	for _, name := range order {
+		e := reg.moduleEmitter(b, name, s)
+		m := module{name: name}
+		exported := make(map[string]bool)
+
+		body := strings.Builder{}
+		for _, d := range grouped[name] {
+			if exported[d.Name] {
+				return nil, fmt.Errorf("gut: %v is declared more than once in the module %v", d.Name, name)
+			}
+			exported[d.Name] = true
+			m.exports = append(m.exports, d.Name)
+
+			body.WriteString(e.parseStruct(d))
+		}
+
+		// the type guards, decoders, ... of the other modules are imported as values
+		values := valueImports(body.String(), name, e.referenced, b.modules)
+
+		imports, err := reg.imports(b, e, name, values)
+		if err != nil {
+			return nil, err
+		}
+		m.content = imports + body.String()
+		m.used = e.used
+		for _, match := range exportedValueExp.FindAllStringSubmatch(body.String(), -1) {
+			m.values = append(m.values, match[1])
+		}
+		modules = append(modules, m)
+	}
+
+	if len(b.graph.Endpoints) > 0 || len(b.graph.Services) > 0 {
+		name := reg.clientModule()
+		if _, ok := grouped[name]; ok {
+			return nil, fmt.Errorf("gut: the client module collides with the module called %v", name)
+		}
+
+		e := reg.moduleEmitter(b, name, s)
+		content := e.client(b.graph)
+
+		// the decode / encode functions of the types are imported as values
+		values := valueImports(content, name, e.referenced, b.modules)
+
+		imports, err := reg.imports(b, e, name, values)
+		if err != nil {
+			return nil, err
+		}
+
+		m := module{name: name, content: imports + content, used: e.used}
+		for _, match := range exportedTypeExp.FindAllStringSubmatch(content, -1) {
+			m.exports = append(m.exports, match[1])
+		}
+		for _, match := range exportedValueExp.FindAllStringSubmatch(content, -1) {
+			m.values = append(m.values, match[1])
+		}
+		modules = append(modules, m)
+	}
+
+	return modules, nil
+}
+
+// moduleEmitter returns the emitter of the module. With NamingNamespace,
+// the references to the other modules are qualified with their name.
+// The aliases are qualified with the prefix and the namespace from s.
+func (reg *Registry) moduleEmitter(b *registryBuild, name string, s Settings) *tsEmitter {
+	e := newTSEmitter(b.graph, b.settings)
+	e.qualifier = aliasQualifier(s)
+	if reg.Naming == NamingNamespace {
+		e.namespaces = b.modules
+		e.namespace = name
+	}
+	return e
+}
+
+// imports returns the import statements of the types which were referenced
+// by the emitter and are declared in other modules, followed by the imports
+// of the values. An error is returned if the imported names collide.
+func (reg *Registry) imports(b *registryBuild, e *tsEmitter, name string, values map[string][]string) (string, error) {
+	if reg.Naming == NamingNamespace {
+		return reg.namespaceImports(b, e, name, values)
+	}
+
+	// modules of the names which are declared in or imported into the module
+	names := make(map[string]string)
+	for _, d := range b.order {
+		if b.modules[d] == name {
+			names[d.Name] = name
+		}
+	}
+
+	imports := make(map[string][]string)
+	for _, d := range b.graph.Decls {
+		from := b.modules[d]
+		if !e.referenced[d] || from == name {
+			continue
+		}
+		if other, ok := names[d.Name]; ok && other != from {
+			return "", fmt.Errorf("gut: %v of the module %v collides with %v of the module %v in the module %v (see Registry.Naming)", d.Name, from, d.Name, other, name)
+		}
+		names[d.Name] = from
+		imports[from] = append(imports[from], d.Name)
+	}
+
+	sb := strings.Builder{}
+	for _, from := range sortedKeys(imports) {
+		names := imports[from]
+		sort.Strings(names)
+		sb.WriteString(fmt.Sprintf("import type { %s } from \"%s\"\n", strings.Join(names, ", "), reg.importPath(from)))
+	}
+	for _, from := range sortedKeys(values) {
+		names := values[from]
+		sort.Strings(names)
+		sb.WriteString(fmt.Sprintf("import { %s } from \"%s\"\n", strings.Join(names, ", "), reg.importPath(from)))
+	}
+	if sb.Len() > 0 {
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// namespaceImports returns the imports of the other modules as namespaces
+// (import type * as billing from "./billing")
+func (reg *Registry) namespaceImports(b *registryBuild, e *tsEmitter, name string, values map[string][]string) (string, error) {
+	modules := make(map[string]bool)
+	for d := range e.referenced {
+		if from := b.modules[d]; from != name {
+			modules[from] = true
+		}
+	}
+
+	sb := strings.Builder{}
+	for _, from := range sortedKeys(modules) {
+		if !isValidTypeName(from) {
+			return "", fmt.Errorf("gut: %q is not a valid typescript namespace", from)
+		}
+		if len(values[from]) > 0 {
+			sb.WriteString(fmt.Sprintf("import * as %s from \"%s\"\n", from, reg.importPath(from)))
+		} else {
+			sb.WriteString(fmt.Sprintf("import type * as %s from \"%s\"\n", from, reg.importPath(from)))
+		}
+	}
+	if sb.Len() > 0 {
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// matches the functions and constants (like the type guards) which are
+// declared next to the types
+var exportedValueExp = regexp.MustCompile(`(?m)^export (?:function|const|class) (\w+)`)
+
+// matches the interfaces of the client and of the services
+var exportedTypeExp = regexp.MustCompile(`(?m)^export (?:interface|type) (\w+)`)
+
+func (reg *Registry) clientModule() string {
+	if reg.Client == "" {
+		return "client"
+	}
+	return reg.Client
+}
+
+// moduleName returns the name of the module in which the type
+// from the Go package is declared, if the module was not set explicitly.
+func (reg *Registry) moduleName(module string, pkgPath string) string {
+	if module != "" {
+		return module
+	}
+	if name, ok := reg.Modules[pkgPath]; ok {
+		return name
+	}
+	if pkgPath == "" {
+		return "types"
+	}
+	return path.Base(pkgPath)
+}
+
+func (reg *Registry) extension() string {
+	if reg.Extension == "" {
+		return ".ts"
+	}
+	return reg.Extension
+}
+
+func (reg *Registry) filename(module string) string {
+	return module + reg.extension()
+}
+
+// importPath returns the relative path which is used to import the module
+func (reg *Registry) importPath(module string) string {
+	return "./" + strings.TrimSuffix(reg.filename(module), ".ts")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
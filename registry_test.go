@@ -0,0 +1,162 @@
+package gut
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	. "github.com/tompston/gut/types"
+)
+
+func TestRegistryConvert(t *testing.T) {
+	reg := NewRegistry().
+		Add(StructWithReference{}).
+		Add(StructWithArrayOfReferences{}).
+		Add(ReferenceStruct{})
+
+	expected := `
+	export interface StructWithReference {
+		my_str: string
+		MyInt: number
+		ref: ReferenceStruct
+		opt_ref: ReferenceStruct
+	}
+
+	export interface StructWithArrayOfReferences {
+		arr_of_ref: ReferenceStruct[]
+	}
+
+	export interface ReferenceStruct {
+		my_float: number
+		timestamp: number
+	}`
+
+	if generated := reg.Convert(); stripSpaces(generated) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+}
+
+func TestRegistryAnonymousStructs(t *testing.T) {
+	reg := NewRegistry().
+		Add(Order{}, Type{DeclareAnonymous: true, Guard: true, Module: "orders"}).
+		Add(Employees{}, Type{AnonymousName: func(parent, field string) string { return parent + "Row" }})
+
+	out := NewMemFS()
+	if err := reg.GenerateFS(out, Settings{Logger: DiscardLogger}); err != nil {
+		t.Fatal(err)
+	}
+
+	orders, err := out.ReadFile("orders.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the anonymous structs inherit the module and the guard of the parent
+	if !containsAll(string(orders),
+		"export interface OrderCustomerAddress {",
+		"export function isOrderCustomer(x: unknown): x is OrderCustomer {",
+		`isOrderCustomerAddress(x["address"])`,
+	) {
+		t.Errorf("unexpected orders.ts:\n%s", orders)
+	}
+
+	types, err := out.ReadFile("types.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsAll(string(types), "export type Employees = EmployeesRow[]", "export interface EmployeesRow {") {
+		t.Errorf("unexpected types.ts:\n%s", types)
+	}
+}
+
+func TestRegistryNameCollisions(t *testing.T) {
+	reg := NewRegistry().
+		Add(Pair[string, int]{}).
+		Add(PairOfStringAndInt{}, Type{Module: "other"})
+
+	// the modules are separate files, so the names do not collide
+	if err := reg.GenerateFS(NewMemFS(), Settings{Logger: DiscardLogger}); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if err := recover(); err == nil || !strings.Contains(fmt.Sprint(err), "PairOfStringAndInt is declared more than once") {
+			t.Errorf("expected a collision of the mangled name, got %v", err)
+		}
+	}()
+	reg.Convert()
+}
+
+func TestRegistryModules(t *testing.T) {
+	reg := NewRegistry().
+		Add(StructWithReference{}).
+		Add(Employees{}).
+		Add(ReferenceStruct{}, Type{Module: "common"})
+	reg.Index = true
+
+	out := NewMemFS()
+	settings := Settings{Logger: DiscardLogger, DateType: "string"}
+	if err := reg.GenerateFS(out, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"types.ts": `
+			export type UuidType = string
+
+			import type { ReferenceStruct } from "./common"
+
+			export interface StructWithReference {
+				my_str: string
+				MyInt: number
+				ref: ReferenceStruct
+				opt_ref: ReferenceStruct
+			}
+
+			export type Employees = EmployeesItem[]
+
+			export interface EmployeesItem {
+				user_id: UuidType
+				Username: string
+				opt_surename?: string
+				RandomInterface: any
+				opt_interface?: any
+			}`,
+		"common.ts": `
+			export interface ReferenceStruct {
+				my_float: number
+				timestamp: number
+			}`,
+		"index.ts": `
+			export type { StructWithReference, Employees, EmployeesItem } from "./types"
+			export type { ReferenceStruct } from "./common"`,
+	}
+
+	if names := out.Names(); len(names) != len(tests) {
+		t.Fatalf("expected %v files, got: %v\n", len(tests), names)
+	}
+
+	for name, expected := range tests {
+		data, err := fs.ReadFile(out, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stripSpaces(string(data)) != stripSpaces(expected) {
+			t.Fatalf("%v expected: %v\n, got: %v\n", name, expected, string(data))
+		}
+	}
+
+	if diff, err := reg.CheckFS(out, settings); err != nil || diff != "" {
+		t.Fatalf("expected up to date modules, got: %v %v\n", diff, err)
+	}
+}
+
+func TestRegistryDuplicateNames(t *testing.T) {
+	reg := NewRegistry().
+		Add(SimpleStruct{}).
+		Add(SimpleStructWithJsonTags{}, Type{Name: "SimpleStruct"})
+
+	if err := reg.GenerateFS(NewMemFS(), Settings{Logger: DiscardLogger}); err == nil {
+		t.Fatal("expected an error for the duplicate interface names")
+	}
+}
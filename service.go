@@ -0,0 +1,199 @@
+package gut
+
+import (
+	"context"
+	"fmt"
+	r "reflect"
+	"strings"
+
+	"github.com/tompston/gut/ir"
+)
+
+// ServiceType describes a set of remote procedures, which is
+// created with the Service function.
+type ServiceType struct {
+	typ     r.Type
+	methods []r.Method
+}
+
+var (
+	contextType = r.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = r.TypeOf((*error)(nil)).Elem()
+)
+
+// Service creates a JSON-RPC 2.0 service from the methods of the interface
+// T. Every method has to be shaped like func(ctx, Req) (Resp, error),
+// where the request and the response can also be omitted.
+//
+// When the service is added to a Registry, an `export interface T` with
+// the methods (which return a Promise of the response) and a
+// create<T>Client function are emitted. The methods are called as
+// "<T>.<Method>" and the request is sent as the params of the call.
+//
+// Example
+//
+//	type UserService interface {
+//		GetUser(ctx context.Context, req GetUserRequest) (User, error)
+//	}
+//
+//	reg.Add(gut.Service[UserService]())
+//	// const users = createUserServiceClient({ url: "/rpc" })
+//	// const user = await users.getUser({ id: 1 })
+func Service[T any]() ServiceType {
+	typ := r.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != r.Interface {
+		panic(fmt.Sprintf("Only interfaces can be converted to a service! %v", typ))
+	}
+	if typ.NumMethod() == 0 {
+		panic(fmt.Sprintf("The service %v does not have any methods!", typ))
+	}
+
+	s := ServiceType{typ: typ}
+	for i := 0; i < typ.NumMethod(); i++ {
+		m := typ.Method(i)
+		if !isServiceMethod(m.Type) {
+			panic(fmt.Sprintf("The method %v.%v has to be shaped like func(ctx, Req) (Resp, error)!", typ.Name(), m.Name))
+		}
+		s.methods = append(s.methods, m)
+	}
+	return s
+}
+
+// isServiceMethod reports whether the method is shaped
+// like func(ctx, [Req]) ([Resp], error)
+func isServiceMethod(t r.Type) bool {
+	if t.NumIn() < 1 || t.NumIn() > 2 || t.In(0) != contextType || t.IsVariadic() {
+		return false
+	}
+	return t.NumOut() >= 1 && t.NumOut() <= 2 && t.Out(t.NumOut()-1) == errorType
+}
+
+// serviceTypes returns the request and the response type of the method
+// (nil if they are omitted)
+func serviceTypes(m r.Method) (req r.Type, resp r.Type) {
+	if m.Type.NumIn() == 2 {
+		req = m.Type.In(1)
+	}
+	if m.Type.NumOut() == 2 {
+		resp = m.Type.Out(0)
+	}
+	return req, resp
+}
+
+// declareServiceTypes declares the named structs which are used by the
+// methods of the service (if they are not declared yet).
+func declareServiceTypes(b *ir.Builder, s ServiceType) []*ir.Decl {
+	var decls []*ir.Decl
+	for _, m := range s.methods {
+		req, resp := serviceTypes(m)
+		decls = append(decls, declareNamedStructs(b, req, resp)...)
+	}
+	return decls
+}
+
+// addService converts the types of the methods and adds the service to the graph
+func addService(b *ir.Builder, s ServiceType, name string) {
+	built := &ir.Service{Name: name}
+	for _, m := range s.methods {
+		method := &ir.Method{Name: m.Name}
+		req, resp := serviceTypes(m)
+		if req != nil {
+			method.Params = b.Type(req)
+		}
+		if resp != nil {
+			method.Result = b.Type(resp)
+		}
+		built.Methods = append(built.Methods, method)
+	}
+	b.AddService(built)
+}
+
+// serviceClients returns the interfaces of the services
+// and the functions which create their clients.
+func (e *tsEmitter) serviceClients(services []*ir.Service) string {
+	sb := strings.Builder{}
+	sb.WriteString(rpcPrelude)
+
+	for _, s := range services {
+		signatures := strings.Builder{}
+		calls := strings.Builder{}
+
+		for _, m := range s.Methods {
+			name := camelCase(m.Name)
+
+			args, params := "", "undefined"
+			if m.Params != nil {
+				args = fmt.Sprintf("params: %s", e.toTS(m.Params))
+				params = "params"
+				if conv := (codec{e: e, dir: "encode", visiting: map[*ir.Decl]bool{}}).convert(m.Params, "params", 0); conv != "" {
+					params = conv
+				}
+			}
+
+			result, then := "void", ""
+			if m.Result != nil {
+				result = e.toTS(m.Result)
+				if conv := (codec{e: e, dir: "decode", visiting: map[*ir.Decl]bool{}}).convert(m.Result, "v", 0); conv != "" {
+					then = fmt.Sprintf(".then((v: any) => %s)", conv)
+				}
+			}
+
+			signatures.WriteString(fmt.Sprintf("  %s(%s): Promise<%s>\n", name, args, result))
+			calls.WriteString(fmt.Sprintf("    %s: (%s): Promise<%s> =>\n", name, args, result))
+			calls.WriteString(fmt.Sprintf("      call<%s>(%q, %s)%s,\n", result, s.Name+"."+m.Name, params, then))
+		}
+
+		sb.WriteString(fmt.Sprintf("export interface %s {\n%s}\n\n", s.Name, signatures.String()))
+		sb.WriteString(fmt.Sprintf("export function create%sClient(options: RpcClientOptions): %s {\n", s.Name, s.Name))
+		sb.WriteString("  const call = rpcCaller(options)\n\n")
+		sb.WriteString(fmt.Sprintf("  return {\n%s  }\n}\n\n", calls.String()))
+	}
+
+	return sb.String()
+}
+
+const rpcPrelude = `export class RpcError extends Error {
+  code: number
+  data: unknown
+
+  constructor(code: number, message: string, data?: unknown) {
+    super(message)
+    this.code = code
+    this.data = data
+  }
+}
+
+export interface RpcClientOptions {
+  // url of the json-rpc endpoint (e.g. "https://example.com/rpc")
+  url: string
+  // headers which are sent with every request
+  headers?: Record<string, string>
+  // custom implementation of fetch
+  fetch?: typeof fetch
+}
+
+function rpcCaller(options: RpcClientOptions) {
+  const doFetch = options.fetch ?? fetch
+  let id = 0
+
+  return async function call<T>(method: string, params: unknown): Promise<T> {
+    const request: Record<string, unknown> = { jsonrpc: "2.0", id: ++id, method }
+    if (params !== undefined) {
+      // the params of a json-rpc call have to be an object or an array
+      request.params = typeof params === "object" && params !== null && !Array.isArray(params) ? params : [params]
+    }
+
+    const res = await doFetch(options.url, {
+      method: "POST",
+      headers: { ...options.headers, "Content-Type": "application/json" },
+      body: JSON.stringify(request),
+    })
+    if (!res.ok) throw new RpcError(res.status, ` + "`request failed with status ${res.status}`" + `)
+
+    const body = await res.json()
+    if (body.error) throw new RpcError(body.error.code, body.error.message, body.error.data)
+    return body.result as T
+  }
+}
+
+`
@@ -0,0 +1,106 @@
+package gut
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/tompston/gut/types"
+)
+
+func TestServiceClient(t *testing.T) {
+	reg := NewRegistry().
+		Add(SimpleStructWithTimeFields{}, Type{Codec: true}).
+		Add(Service[UserService]())
+
+	generated := reg.Convert()
+
+	for _, expected := range []string{
+		"export interface GetUserRequest {",
+		"export class RpcError extends Error {",
+		"export interface UserService {\n" +
+			"  createUser(params: CreateUserRequest): Promise<ReferenceStruct>\n" +
+			"  deleteUser(params: GetUserRequest): Promise<void>\n" +
+			"  getUser(params: GetUserRequest): Promise<SimpleStructWithTimeFields>\n" +
+			"  listUsers(): Promise<SimpleStructWithTimeFields[]>\n" +
+			"}",
+		"export function createUserServiceClient(options: RpcClientOptions): UserService {",
+		"deleteUser: (params: GetUserRequest): Promise<void> =>\n" +
+			"      call<void>(\"UserService.DeleteUser\", params),",
+		"getUser: (params: GetUserRequest): Promise<SimpleStructWithTimeFields> =>\n" +
+			"      call<SimpleStructWithTimeFields>(\"UserService.GetUser\", params).then((v: any) => decodeSimpleStructWithTimeFields(v)),",
+		"listUsers: (): Promise<SimpleStructWithTimeFields[]> =>\n" +
+			"      call<SimpleStructWithTimeFields[]>(\"UserService.ListUsers\", undefined)",
+	} {
+		if !containsAll(generated, expected) {
+			t.Errorf("expected the output to contain\n%v\ngot:\n%v", expected, generated)
+		}
+	}
+
+	// the client of the endpoints is not emitted without endpoints
+	if containsAll(generated, "createClient(") {
+		t.Errorf("unexpected endpoint client:\n%v", generated)
+	}
+}
+
+func TestServiceModules(t *testing.T) {
+	reg := NewRegistry().
+		Add(SimpleStructWithTimeFields{}, Type{Module: "common"}).
+		Add(Service[UserService](), Type{Name: "Users"})
+	reg.Index = true
+
+	out := NewMemFS()
+	if err := reg.GenerateFS(out, Settings{Logger: DiscardLogger}); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := out.ReadFile("client.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsAll(string(client),
+		`import type { SimpleStructWithTimeFields } from "./common"`,
+		`export interface Users {`,
+		`call<void>("Users.DeleteUser", params)`,
+	) {
+		t.Errorf("unexpected client:\n%s", client)
+	}
+
+	index, err := out.ReadFile("index.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsAll(string(index),
+		`export type { RpcClientOptions, Users } from "./client"`,
+		`export { RpcError, createUsersClient } from "./client"`,
+	) {
+		t.Errorf("unexpected index:\n%s", index)
+	}
+}
+
+type invalidService interface {
+	Get(id int) (string, error)
+}
+
+type serviceWithoutError interface {
+	Get(ctx context.Context, id int) string
+}
+
+func TestInvalidServices(t *testing.T) {
+	tests := []func(){
+		func() { Service[invalidService]() },
+		func() { Service[serviceWithoutError]() },
+		func() { Service[SimpleStruct]() },
+		func() { NewRegistry().Add(Service[UserService]()).Add(Service[UserService]()) },
+	}
+
+	for i, fn := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic in test %d", i)
+				}
+			}()
+			fn()
+		}()
+	}
+}
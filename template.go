@@ -0,0 +1,179 @@
+package gut
+
+import (
+	"io"
+	r "reflect"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/tompston/gut/ir"
+)
+
+// TemplateData is passed to the templates which are rendered
+// with Registry.RenderTemplate.
+type TemplateData struct {
+	Decls []TemplateDecl
+}
+
+// TemplateDecl is a single declaration of the registry.
+type TemplateDecl struct {
+	Name string
+	// "struct" or "alias"
+	Kind string
+	// Go type from which the declaration was created
+	GoType string
+	// typescript type of the declaration, if it is an alias
+	TSType string
+	// fields of the struct (with the fields of the inlined structs)
+	Fields []TemplateField
+}
+
+// TemplateField is a single field of a declaration.
+type TemplateField struct {
+	// Name of the field in Go
+	Name     string
+	JSONName string
+	Optional bool
+	TSType   string
+	GoType   string
+	Tag      r.StructTag
+}
+
+// templateFuncs are the helper functions which are available in the templates
+var templateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"join":  strings.Join,
+	"quote": quote,
+	"camel": camelCase,
+	"snake": snakeCase,
+	// returns the value of the key in the struct tag (e.g. {{ tag .Tag "db" }})
+	"tag": func(tag r.StructTag, key string) string { return tag.Get(key) },
+}
+
+// NewTemplate parses the text/template with the helper functions of gut
+// (lower, upper, join, quote, camel, snake and tag). The template is
+// executed with TemplateData.
+//
+// Example
+//
+//	tmpl := gut.MustTemplate("fields", `{{ range .Decls }}
+//	export const {{ .Name }}Fields = [{{ range .Fields }}{{ quote .JSONName }}, {{ end }}] as const
+//	{{ end }}`)
+func NewTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+// MustTemplate works like NewTemplate, but panics if the template is invalid.
+func MustTemplate(name, text string) *template.Template {
+	return template.Must(NewTemplate(name, text))
+}
+
+// RenderTemplate executes the template with the registered structs
+// and writes the output to w.
+func (reg *Registry) RenderTemplate(w io.Writer, tmpl *template.Template) error {
+	data, err := reg.templateData()
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
+
+// templateData converts the registered structs into TemplateData
+func (reg *Registry) templateData() (*TemplateData, error) {
+	b, err := reg.build()
+	if err != nil {
+		return nil, err
+	}
+
+	e := newTSEmitter(b.graph, b.settings)
+	data := &TemplateData{}
+
+	for _, d := range b.order {
+		decl := TemplateDecl{Name: d.Name, Kind: d.Kind.String(), GoType: goTypeName(d.Go)}
+		if d.Kind == ir.AliasDecl {
+			decl.TSType = e.toTS(d.Type)
+		}
+		decl.Fields = templateFields(e, d.Fields)
+		data.Decls = append(data.Decls, decl)
+	}
+
+	return data, nil
+}
+
+func templateFields(e *tsEmitter, fields []*ir.Field) []TemplateField {
+	var converted []TemplateField
+	for _, f := range fields {
+		if f.Inline {
+			converted = append(converted, templateFields(e, f.Type.Fields)...)
+			continue
+		}
+		converted = append(converted, TemplateField{
+			Name:     f.Name,
+			JSONName: f.JSONName,
+			Optional: f.Optional,
+			TSType:   e.fieldTS(f),
+			GoType:   goTypeName(f.Type.Go),
+			Tag:      f.Tag,
+		})
+	}
+	return converted
+}
+
+func goTypeName(t r.Type) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
+
+// camelCase converts the name into camelCase (e.g. "user_id" -> "userId")
+func camelCase(name string) string {
+	words := splitWords(name)
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if i > 0 && w != "" {
+			w = strings.ToUpper(w[:1]) + w[1:]
+		}
+		words[i] = w
+	}
+	return strings.Join(words, "")
+}
+
+// snakeCase converts the name into snake_case (e.g. "UserID" -> "user_id")
+func snakeCase(name string) string {
+	words := splitWords(name)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, "_")
+}
+
+// splitWords splits the name on underscores, dashes, spaces and
+// on the changes of the case (keeping the acronyms together).
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+
+	for i := 0; i <= len(runes); i++ {
+		if i == len(runes) || runes[i] == '_' || runes[i] == '-' || runes[i] == ' ' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i > start && unicode.IsUpper(runes[i]) {
+			prevLower := !unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])
+			if prevLower || nextLower {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+
+	return words
+}
@@ -0,0 +1,79 @@
+package gut
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	. "github.com/tompston/gut/types"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	reg := NewRegistry().Add(StructWithReference{}).Add(ReferenceStruct{})
+
+	tmpl := MustTemplate("fields", `{{ range .Decls }}
+export const {{ .Name }}Fields = [{{ range .Fields }}{{ quote .JSONName }}, {{ end }}] as const
+{{ end }}`)
+
+	sb := strings.Builder{}
+	if err := reg.RenderTemplate(&sb, tmpl); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+	export const StructWithReferenceFields = ["my_str", "MyInt", "ref", "opt_ref", ] as const
+	export const ReferenceStructFields = ["my_float", "timestamp", ] as const`
+
+	if stripSpaces(sb.String()) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, sb.String())
+	}
+}
+
+func TestTemplateFields(t *testing.T) {
+	reg := NewRegistry().Add(StructWithInlinedFields{})
+	reg.Templates = map[string]*template.Template{
+		"types.md": MustTemplate("markdown", `{{ range .Decls }}| {{ .Name }} | {{ .GoType }} |
+{{ range .Fields }}| {{ .JSONName }}{{ if .Optional }}?{{ end }} | {{ .TSType }} | {{ .GoType }} | {{ snake .Name }} | {{ tag .Tag "json" }} |
+{{ end }}{{ end }}`),
+	}
+
+	out := NewMemFS()
+	if err := reg.GenerateFS(out, Settings{Logger: DiscardLogger}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := out.ReadFile("types.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"| StructWithInlinedFields | types.StructWithInlinedFields |",
+		"| start_time | DateType | time.Time | start_time | start_time |",
+		"| CustomField | number | int | custom_field |  |",
+	} {
+		if !strings.Contains(string(content), line) {
+			t.Errorf("the output does not contain %q\n%s", line, content)
+		}
+	}
+}
+
+func TestCaseConversion(t *testing.T) {
+	tests := []struct {
+		name, camel, snake string
+	}{
+		{"user_id", "userId", "user_id"},
+		{"UserID", "userId", "user_id"},
+		{"HTTPServer", "httpServer", "http_server"},
+		{"some-value", "someValue", "some_value"},
+	}
+
+	for _, tt := range tests {
+		if got := camelCase(tt.name); got != tt.camel {
+			t.Errorf("camelCase(%q) = %q, expected %q", tt.name, got, tt.camel)
+		}
+		if got := snakeCase(tt.name); got != tt.snake {
+			t.Errorf("snakeCase(%q) = %q, expected %q", tt.name, got, tt.snake)
+		}
+	}
+}
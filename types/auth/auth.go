@@ -0,0 +1,10 @@
+package auth
+
+type User struct {
+	Email  string `json:"email"`
+	Config Config `json:"config"`
+}
+
+type Config struct {
+	MFA bool `json:"mfa"`
+}
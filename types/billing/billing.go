@@ -0,0 +1,13 @@
+package billing
+
+import "github.com/tompston/gut/types/auth"
+
+type User struct {
+	ID      int       `json:"id"`
+	Account auth.User `json:"account"`
+	Config  Config    `json:"config"`
+}
+
+type Config struct {
+	Currency string `json:"currency"`
+}
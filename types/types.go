@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"time"
 
 	"github.com/google/uuid"
@@ -105,3 +106,224 @@ type GenericWithAnObject StructWithGeneric[map[string]interface{}]
 type GenericWithAnArray StructWithGeneric[[]string]
 type GenericInsideGeneric StructWithGeneric[GenericWithAnObject]
 type GenericInsideGenericInsideGeneric StructWithGeneric[GenericInsideGeneric]
+
+type Event interface {
+	isEvent()
+}
+
+type UserCreated struct {
+	Type     string `json:"type" gut:"literal=user.created"`
+	Username string `json:"username"`
+}
+
+type UserDeleted struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type UserRenamed struct {
+	Type    string `json:"type"`
+	NewName string `json:"new_name"`
+}
+
+func (UserCreated) isEvent() {}
+func (UserDeleted) isEvent() {}
+func (UserRenamed) isEvent() {}
+
+func (UserDeleted) Discriminator() string { return "deleted" }
+
+type EventLog struct {
+	Events []Event `json:"events"`
+	Last   Event   `json:"last,omitempty"`
+}
+
+type StructWithOverriddenField struct {
+	MyEmbeddedStruct `json:",inline"`
+	UpdatedAt        string `json:"updated_at"`
+}
+
+type StructWithTimestamps struct {
+	ID        int64                `json:"id,string"`
+	Counter   uint64               `json:"counter"`
+	CreatedAt time.Time            `json:"created_at"`
+	DeletedAt *time.Time           `json:"deleted_at,omitempty"`
+	History   []time.Time          `json:"history"`
+	Reference ReferenceStruct      `json:"ref"`
+	Expires   map[string]time.Time `json:"expires"`
+	Events    []Event              `json:"events"`
+}
+
+type ListUsersQuery struct {
+	Page  int      `json:"page,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+type CreateUserRequest struct {
+	Username string `json:"username"`
+}
+
+type GetUserRequest struct {
+	ID int `json:"id"`
+}
+
+type UserService interface {
+	GetUser(ctx context.Context, req GetUserRequest) (SimpleStructWithTimeFields, error)
+	CreateUser(ctx context.Context, req CreateUserRequest) (*ReferenceStruct, error)
+	ListUsers(ctx context.Context) ([]SimpleStructWithTimeFields, error)
+	DeleteUser(ctx context.Context, req GetUserRequest) error
+}
+
+type PluginHook func(ctx context.Context, event string) error
+
+type Plugin struct {
+	Name      string                                                    `json:"name"`
+	OnEvent   PluginHook                                                `json:"on_event"`
+	Transform func(input []byte, opts ...string) ([]byte, int64, error) `json:"transform"`
+	Validate  func(value interface{}) error                             `json:"validate,omitempty"`
+	OnError   func(err error)                                           `json:"on_error"`
+	Listeners []func() bool                                             `json:"listeners"`
+}
+
+type StructWithChannel struct {
+	Events chan string
+}
+
+type UserIndex map[string]SimpleStructWithTimeFields
+
+type IDs []int64
+
+type UserPointers []*SimpleStruct
+
+type Status string
+
+type Matrix [][]float64
+
+type EmployeePointers []*struct {
+	Name string `json:"name"`
+}
+
+type Order struct {
+	ID       int `json:"id"`
+	Customer struct {
+		Name    string `json:"name"`
+		Address struct {
+			City string `json:"city"`
+		} `json:"address"`
+	} `json:"customer"`
+	Items []struct {
+		SKU string `json:"sku"`
+		Qty int    `json:"qty"`
+	} `json:"items"`
+	// same shape as the customer
+	Billing *struct {
+		Name    string `json:"name"`
+		Address struct {
+			City string `json:"city"`
+		} `json:"address"`
+	} `json:"billing,omitempty"`
+}
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// has the same name as the mangled Pair[string, int]
+type PairOfStringAndInt struct {
+	Key string `json:"key"`
+}
+
+// The optional fields follow encoding/json: omitempty does not
+// omit struct values (or arrays with elements), omitzero does.
+type StructWithOmittedFields struct {
+	Str           string            `json:"str,omitempty"`
+	Num           int               `json:"num,omitempty"`
+	Ptr           *ReferenceStruct  `json:"ptr,omitempty"`
+	Slice         []string          `json:"slice,omitempty"`
+	Map           map[string]string `json:"map,omitempty"`
+	Iface         interface{}       `json:"iface,omitempty"`
+	Array         [2]int            `json:"array,omitempty"`
+	EmptyArray    [0]int            `json:"empty_array,omitempty"`
+	Ref           ReferenceStruct   `json:"ref,omitempty"`
+	Time          time.Time         `json:"time,omitempty"`
+	ZeroNum       int               `json:"zero_num,omitzero"`
+	ZeroArray     [2]int            `json:"zero_array,omitzero"`
+	ZeroRef       ReferenceStruct   `json:"zero_ref,omitzero"`
+	ZeroTime      time.Time         `json:"zero_time,omitempty,omitzero"`
+	ZeroDate      time.Time         `json:"zero_date,omitzero"`
+	RequiredRef   ReferenceStruct   `json:"required_ref"`
+	RequiredSlice []string          `json:"required_slice"`
+}
+
+type Account struct {
+	ID        int64     `json:"id,string" gut:"server"`
+	CreatedAt time.Time `json:"created_at" gut:"server"`
+	Email     string    `json:"email" gut:"readonly"`
+	Name      string    `json:"name"`
+	Password  string    `json:"password,omitempty"`
+	Profile   Profile   `json:"profile"`
+	Settings  *struct {
+		Theme string `json:"theme"`
+	} `json:"settings,omitempty"`
+	Tags []string `json:"tags"`
+}
+
+type Profile struct {
+	Bio     string `json:"bio"`
+	Website string `json:"website"`
+}
+
+type UserID uuid.UUID
+
+func (id UserID) MarshalText() ([]byte, error) { return uuid.UUID(id).MarshalText() }
+
+func (id *UserID) UnmarshalText(b []byte) error { return (*uuid.UUID)(id).UnmarshalText(b) }
+
+// a named array of 16 bytes, which is marshalled as an array of numbers
+type Checksum [16]byte
+
+type OrgID int64
+
+type ProjectID string
+
+type Membership struct {
+	User    UserID     `json:"user"`
+	Org     OrgID      `json:"org"`
+	Project *ProjectID `json:"project,omitempty"`
+	Owners  []UserID   `json:"owners"`
+}
+
+const (
+	StatusActive   Status = "active"
+	StatusDisabled Status = "disabled"
+)
+
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityHigh
+)
+
+func (p Priority) String() string {
+	if p == PriorityHigh {
+		return "High"
+	}
+	return "Low"
+}
+
+type Ticket struct {
+	Status   Status   `json:"status"`
+	Priority Priority `json:"priority"`
+}
+
+type StructWithBytes struct {
+	Data     []byte   `json:"data"`
+	Chunks   [][]byte `json:"chunks"`
+	Checksum [4]byte  `json:"checksum"`
+}
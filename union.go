@@ -0,0 +1,160 @@
+package gut
+
+import (
+	"fmt"
+	r "reflect"
+	"strings"
+
+	"github.com/tompston/gut/ir"
+)
+
+// UnionType describes a discriminated union of structs, which is
+// created with the Union function.
+type UnionType struct {
+	// type of the union (usually an interface)
+	typ           r.Type
+	variants      []r.Type
+	discriminator string
+}
+
+// Union creates a discriminated union of the variants, which is converted
+// into `export type T = A | B`. Every variant is also declared and its
+// discriminator field ("type" by default) is converted to the literal
+// value of the variant. The literal value is taken from
+//   - the `gut:"literal=..."` tag of the discriminator field
+//   - the `Discriminator() string` method of the variant
+//   - the snake_case name of the variant, if neither is present
+//
+// Every variant must have the discriminator field, since it is not added
+// to the marshalled JSON. Convert panics and the Registry returns an error
+// otherwise.
+//
+// When the union is added to a Registry, the fields of the type T
+// reference the union, instead of being converted to any.
+//
+// Example
+//
+//	type Event interface{ isEvent() }
+//
+//	reg.Add(gut.Union[Event](Created{}, Deleted{}))
+//	// export type Event = Created | Deleted
+//	// export interface Created { type: "created" ... }
+func Union[T any](variants ...interface{}) UnionType {
+	typ := r.TypeOf((*T)(nil)).Elem()
+
+	u := UnionType{typ: typ, discriminator: "type"}
+
+	for _, v := range variants {
+		t := r.TypeOf(v)
+		if t == nil || t.Kind() != r.Struct {
+			panic(fmt.Sprintf("Only structs can be the variants of a union! %v", t))
+		}
+		if typ.Kind() == r.Interface && !t.Implements(typ) && !r.PtrTo(t).Implements(typ) {
+			panic(fmt.Sprintf("%v does not implement %v!", t, typ))
+		}
+		u.variants = append(u.variants, t)
+	}
+
+	return u
+}
+
+// Discriminator returns the union with a custom name of the
+// discriminator field. (Default = "type")
+func (u UnionType) Discriminator(field string) UnionType {
+	u.discriminator = field
+	return u
+}
+
+// declareUnion adds the union (and its variants which are not declared
+// yet) to the builder, and returns the new declarations in the order of
+// emission.
+func declareUnion(b *ir.Builder, u UnionType, name string) []*ir.Decl {
+	var decls, variants []*ir.Decl
+
+	for _, t := range u.variants {
+		d := b.Decl(t)
+		if d == nil {
+			d = b.Declare(t, ir.TypeName(t))
+			decls = append(decls, d)
+		}
+		variants = append(variants, d)
+	}
+
+	union := b.DeclareUnion(u.typ, name, u.discriminator, variants...)
+	return append([]*ir.Decl{union}, decls...)
+}
+
+// applyDiscriminators converts the discriminator fields of the variants
+// of all of the unions in the graph into literal values. An error is
+// returned if a variant does not have the field (it is not marshalled).
+func applyDiscriminators(graph *ir.Graph) error {
+	for _, d := range graph.Decls {
+		if d.Kind != ir.AliasDecl || d.Type.Kind != ir.Union || d.Type.Discriminator == "" {
+			continue
+		}
+
+		for _, v := range d.Type.Variants {
+			variant := v.Decl
+			if variant == nil || variant.Kind != ir.StructDecl {
+				continue
+			}
+
+			field := findField(variant.Fields, d.Type.Discriminator)
+			if field == nil {
+				return fmt.Errorf("gut: %v (a variant of %v) does not have the discriminator field %q", variant.Name, d.Name, d.Type.Discriminator)
+			}
+
+			field.Optional = false
+			field.Stringified = false
+			field.Type = &ir.Type{Kind: ir.Literal, Literal: variantLiteral(variant.Go, field)}
+		}
+	}
+	return nil
+}
+
+// findField returns the field with the json name (including
+// the fields of the inlined structs).
+func findField(fields []*ir.Field, jsonName string) *ir.Field {
+	for _, f := range fields {
+		if f.Inline {
+			if found := findField(f.Type.Fields, jsonName); found != nil {
+				return found
+			}
+		} else if f.JSONName == jsonName {
+			return f
+		}
+	}
+	return nil
+}
+
+type discriminated interface {
+	Discriminator() string
+}
+
+// variantLiteral returns the value of the discriminator field of the variant
+func variantLiteral(t r.Type, field *ir.Field) string {
+	if literal, ok := gutTagOption(field.Tag, "literal"); ok {
+		return literal
+	}
+
+	if t != nil {
+		if v, ok := r.New(t).Interface().(discriminated); ok {
+			return v.Discriminator()
+		}
+		return snakeCase(ir.TypeName(t))
+	}
+
+	return ""
+}
+
+// gutTagOption returns the value of the option in the `gut:"..."` tag.
+// Options are separated by commas and can hold values (`gut:"literal=x"`).
+func gutTagOption(tag r.StructTag, option string) (string, bool) {
+	for _, opt := range strings.Split(tag.Get("gut"), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		if key == option {
+			return value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,85 @@
+package gut
+
+import (
+	"testing"
+
+	. "github.com/tompston/gut/types"
+)
+
+func TestUnionConversion(t *testing.T) {
+	generated := Convert(Union[Event](UserCreated{}, UserDeleted{}, UserRenamed{}))
+
+	expected := `
+	export type Event = UserCreated | UserDeleted | UserRenamed
+
+	export interface UserCreated {
+		type: "user.created"
+		username: string
+	}
+
+	export interface UserDeleted {
+		type: "deleted"
+		reason?: string
+	}
+
+	export interface UserRenamed {
+		type: "user_renamed"
+		new_name: string
+	}`
+
+	if stripSpaces(generated) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+}
+
+func TestUnionInRegistry(t *testing.T) {
+	reg := NewRegistry().
+		Add(EventLog{}).
+		Add(Union[Event](UserCreated{}, UserDeleted{}).Discriminator("type")).
+		Add(UserCreated{}, Type{Name: "Created"})
+
+	expected := `
+	export interface EventLog {
+		events: Event[]
+		last?: Event
+	}
+
+	export type Event = Created | UserDeleted
+
+	export interface Created {
+		type: "user.created"
+		username: string
+	}
+
+	export interface UserDeleted {
+		type: "deleted"
+		reason?: string
+	}`
+
+	if generated := reg.Convert(); stripSpaces(generated) != stripSpaces(expected) {
+		t.Fatalf("expected: %v\n, got: %v\n", expected, generated)
+	}
+}
+
+func TestUnionVariantsMustImplementTheInterface(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+	Union[Event](ReferenceStruct{})
+}
+
+func TestUnionVariantsMustHaveTheDiscriminator(t *testing.T) {
+	reg := NewRegistry().Add(Union[Event](UserCreated{}, UserDeleted{}).Discriminator("kind"))
+	if err := reg.GenerateFS(NewMemFS(), Settings{Logger: DiscardLogger}); err == nil {
+		t.Errorf("expected an error for the variants without the kind field")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+	Convert(Union[Event](UserCreated{}).Discriminator("kind"))
+}
@@ -0,0 +1,150 @@
+package gut
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// yamlNode is a json value, which keeps the order of the object keys
+type yamlNode struct {
+	// set for the objects
+	keys   []string
+	values []*yamlNode
+	// set for the arrays
+	items   []*yamlNode
+	isArray bool
+	// set for the scalars (json encoded)
+	scalar string
+}
+
+// jsonToYAML converts the json document into yaml, keeping
+// the order of the object keys.
+func jsonToYAML(data []byte) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	node, err := decodeYAMLNode(dec)
+	if err != nil {
+		return "", err
+	}
+
+	sb := strings.Builder{}
+	writeYAML(&sb, node, 0)
+	return sb.String(), nil
+}
+
+func decodeYAMLNode(dec *json.Decoder) (*yamlNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		node := &yamlNode{isArray: tok == '['}
+		for dec.More() {
+			if !node.isArray {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, key.(string))
+			}
+
+			value, err := decodeYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			if node.isArray {
+				node.items = append(node.items, value)
+			} else {
+				node.values = append(node.values, value)
+			}
+		}
+		// closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+
+	case string:
+		return &yamlNode{scalar: yamlString(tok)}, nil
+	case nil:
+		return &yamlNode{scalar: "null"}, nil
+	default:
+		return &yamlNode{scalar: fmt.Sprint(tok)}, nil
+	}
+}
+
+// writeYAML writes the node, which is located after a key or a "- "
+func writeYAML(sb *strings.Builder, node *yamlNode, indent int) {
+	prefix := strings.Repeat("  ", indent)
+
+	switch {
+	case node.isArray:
+		for _, item := range node.items {
+			sb.WriteString(prefix)
+			sb.WriteString("-")
+			writeYAMLValue(sb, item, indent+1, true)
+		}
+
+	case node.keys != nil:
+		for i, key := range node.keys {
+			sb.WriteString(prefix)
+			sb.WriteString(yamlString(key))
+			sb.WriteString(":")
+			writeYAMLValue(sb, node.values[i], indent+1, false)
+		}
+
+	default:
+		sb.WriteString(prefix)
+		sb.WriteString(node.scalar)
+		sb.WriteString("\n")
+	}
+}
+
+// writeYAMLValue writes the value of a key (or of an array item)
+func writeYAMLValue(sb *strings.Builder, node *yamlNode, indent int, item bool) {
+	switch {
+	case node.isArray && len(node.items) == 0:
+		sb.WriteString(" []\n")
+
+	case !node.isArray && node.keys == nil && node.scalar == "":
+		sb.WriteString(" {}\n")
+
+	case node.isArray || node.keys != nil:
+		if item && node.keys != nil {
+			// the first key of an object in an array is written after the "- "
+			sub := strings.Builder{}
+			writeYAML(&sub, node, indent)
+			sb.WriteString(" ")
+			sb.WriteString(strings.TrimLeft(sub.String(), " "))
+			return
+		}
+		sb.WriteString("\n")
+		writeYAML(sb, node, indent)
+
+	default:
+		sb.WriteString(" ")
+		sb.WriteString(node.scalar)
+		sb.WriteString("\n")
+	}
+}
+
+var plainYAMLExp = regexp.MustCompile(`^[A-Za-z_/$][A-Za-z0-9_./${}-]*$`)
+
+// yamlString returns the string as a plain yaml scalar, or quoted if
+// it could be parsed as another type (like true or null).
+func yamlString(s string) string {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "y", "n":
+		return quote(s)
+	}
+	if plainYAMLExp.MatchString(s) {
+		return s
+	}
+	return quote(s)
+}
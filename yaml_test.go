@@ -0,0 +1,47 @@
+package gut
+
+import "testing"
+
+func TestJSONToYAML(t *testing.T) {
+	tests := []struct {
+		json     string
+		expected string
+	}{
+		{`{"b":1,"a":"x"}`, "b: 1\na: x\n"},
+		{`{"list":[1,"two",null],"empty":[],"obj":{}}`, "list:\n  - 1\n  - two\n  - null\nempty: []\nobj: {}\n"},
+		{`{"items":[{"a":true,"b":{"c":"d"}}]}`, "items:\n  - a: true\n    b:\n      c: d\n"},
+		{`{"big":12345678901234567890,"f":1.5}`, "big: 12345678901234567890\nf: 1.5\n"},
+	}
+
+	for _, tt := range tests {
+		got, err := jsonToYAML([]byte(tt.json))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.expected {
+			t.Errorf("jsonToYAML(%v) =\n%v\nexpected:\n%v", tt.json, got, tt.expected)
+		}
+	}
+}
+
+func TestYAMLString(t *testing.T) {
+	tests := []struct {
+		value, expected string
+	}{
+		{"plain", "plain"},
+		{"/users/{id}", "/users/{id}"},
+		{"true", `"true"`},
+		{"Null", `"Null"`},
+		{"200", `"200"`},
+		{"", `""`},
+		{"two words", `"two words"`},
+		{"#/components/schemas/User", `"#/components/schemas/User"`},
+		{"a: b", `"a: b"`},
+	}
+
+	for _, tt := range tests {
+		if got := yamlString(tt.value); got != tt.expected {
+			t.Errorf("yamlString(%q) = %v, expected %v", tt.value, got, tt.expected)
+		}
+	}
+}